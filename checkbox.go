@@ -0,0 +1,153 @@
+package hmc
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Checkbox is analogous to HTML's <input type="checkbox">. It represents a
+// choice that is either on or off, such as agreeing to terms.
+//
+// As in HTML, a checked Checkbox is submitted as its Value under its Name,
+// and an unchecked one isn't submitted at all. If Value is empty, "on" is
+// submitted, as a browser does.
+//
+// If Required is set, the Checkbox must be checked.
+type Checkbox struct {
+	Label    string   `json:"label"`
+	Name     string   `json:"name"`
+	Value    string   `json:"value,omitempty"`
+	Checked  bool     `json:"checked,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+func (Checkbox) controlName() string { return "Checkbox" }
+
+func (c *Checkbox) scope(from, to string) { c.Name = rescopeName(c.Name, from, to) }
+
+func (c Checkbox) addFormValues(values url.Values) {
+	if c.Checked {
+		values.Add(c.Name, c.SubmittedValue())
+	}
+}
+
+// SubmittedValue returns the value that the Checkbox is submitted with
+// when it is checked: Value, or "on" if Value is empty.
+func (c Checkbox) SubmittedValue() string {
+	return cmp.Or(c.Value, "on")
+}
+
+// UnmarshalJSON replaces c with the Checkbox in data. An unchecked box
+// omits "checked", so decoding it into a checked Checkbox unchecks it.
+func (c *Checkbox) UnmarshalJSON(data []byte) error {
+	var j checkboxJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*c = Checkbox(j)
+	return nil
+}
+
+// ExtractFormValue checks the Checkbox if [Checkbox.SubmittedValue] is
+// among the values of Name in form, deleting that value from form, and
+// unchecks it otherwise.
+//
+// Since an unchecked checkbox isn't submitted, a missing Name means that
+// the Checkbox is unchecked, so ExtractFormValue should only be called with
+// a submission of the form the Checkbox is in.
+func (c *Checkbox) ExtractFormValue(form url.Values) {
+	values := form[c.Name]
+	i := slices.Index(values, c.SubmittedValue())
+	c.Checked = i >= 0
+	if !c.Checked {
+		return
+	}
+	if len(values) > 1 {
+		form[c.Name] = slices.Delete(slices.Clone(values), i, i+1)
+	} else {
+		delete(form, c.Name)
+	}
+}
+
+// Validate appends a message to [Checkbox.Errors] if the Checkbox is
+// Required but not Checked. The message of an earlier call to Validate is
+// replaced, and other errors that are already present are kept.
+func (c *Checkbox) Validate() {
+	c.Errors = checkboxMessages.clear(c.Errors)
+	if c.Required && !c.Checked {
+		c.Errors = append(c.Errors, fmt.Sprintf(msgUnchecked, c.Name))
+	}
+}
+
+const msgUnchecked = "%#v must be checked"
+
+var checkboxMessages = newMessageFormats(msgUnchecked)
+
+// FirstError returns the first of [Checkbox.Errors], or "" if there are
+// none.
+func (c Checkbox) FirstError() string {
+	return firstError(c.Errors)
+}
+
+// FieldErrors reports each of [Checkbox.Errors].
+func (c Checkbox) FieldErrors() []FieldError {
+	return fieldErrors(c.Name, c.Errors)
+}
+
+func (c Checkbox) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Checkbox"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: c.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: c.Name})
+	if c.Value != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: c.Value})
+	}
+	if c.Checked {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "checked"}})
+	}
+	if c.Required {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "required"}, Value: "true"})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := encodeErrors(e, c.Errors); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:Checkbox element as marshalled by
+// [Checkbox.MarshalXML].
+func (c *Checkbox) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*c = Checkbox{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			c.Label = a.Value
+		case "name":
+			c.Name = a.Value
+		case "value":
+			c.Value = a.Value
+		case "checked":
+			c.Checked = parseBoolAttr(a.Value)
+		case "required":
+			c.Required = parseBoolAttr(a.Value)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Error") {
+			return decodeError(d, child, &c.Errors)
+		}
+		return d.Skip()
+	})
+}
@@ -0,0 +1,44 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func TestCheckboxExtractFormValue(t *testing.T) {
+	c := hmc.Checkbox{Name: "subscribe", Checked: true}
+
+	form := url.Values{"subscribe": {"on"}, "other": {"x"}}
+	c.ExtractFormValue(form)
+	assert.Eq(t, "checked", true, c.Checked)
+	assert.Eq(t, "value is consumed", 1, len(form))
+
+	c.ExtractFormValue(url.Values{})
+	assert.Eq(t, "missing means unchecked", false, c.Checked)
+}
+
+func TestCheckboxValue(t *testing.T) {
+	c := hmc.Checkbox{Name: "colour", Value: "red"}
+
+	form := url.Values{"colour": {"blue", "red"}}
+	c.ExtractFormValue(form)
+	assert.Eq(t, "checked", true, c.Checked)
+	assert.SlicesEq(t, "other values are left", []string{"blue"}, form["colour"])
+
+	c.ExtractFormValue(url.Values{"colour": {"on"}})
+	assert.Eq(t, "other values don't check it", false, c.Checked)
+}
+
+func TestCheckboxValidate(t *testing.T) {
+	c := hmc.Checkbox{Name: "terms", Required: true}
+	c.Validate()
+	assert.SlicesEq(t, "errors", []string{`"terms" must be checked`}, c.Errors)
+
+	c.Checked = true
+	c.Errors = append(c.Errors, "terms have changed")
+	c.Validate()
+	assert.SlicesEq(t, "errors when validated again", []string{"terms have changed"}, c.Errors)
+}
@@ -0,0 +1,311 @@
+// Package client consumes APIs that are described with hmc's hypermedia
+// controls.
+//
+// A [Client] fetches a resource as XML, finds its c:Form, c:Link and
+// c:LinkTemplate controls, and lets you follow links by label or relation
+// type, expand link templates with values, or fill in and submit forms by
+// control name, much as a browser would for HTML. Files are attached to
+// file inputs with [Form.Attach].
+//
+//	c := client.Client{}
+//	doc, err := c.Get(ctx, "https://example.com/login")
+//	form := doc.Forms[0]
+//	err = form.Set("username", "john")
+//	doc, err = c.Submit(ctx, form)
+package client
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Teajey/hmc"
+)
+
+// ErrNoLink is returned when following a link that the document doesn't
+// have.
+var ErrNoLink = errors.New("no such link")
+
+// ErrUnsupportedMediaType is returned when a response isn't XML, or when a
+// form asks to be submitted with an enctype that the client doesn't
+// support.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// accept prefers XML, which is the only representation that identifies
+// each control.
+const accept = "application/xml, text/xml;q=0.9"
+
+// Client fetches and submits hmc documents.
+type Client struct {
+	// HTTPClient is used to make requests. If nil, [http.DefaultClient] is
+	// used.
+	HTTPClient *http.Client
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// Get fetches and parses the document at rawURL.
+func (c *Client) Get(ctx context.Context, rawURL string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Follow fetches the document linked to from doc by the link labelled
+// label, using the link's method.
+func (c *Client) Follow(ctx context.Context, doc *Document, label string) (*Document, error) {
+	link, ok := doc.Link(label)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoLink, label)
+	}
+	return c.FollowLink(ctx, doc, link)
+}
+
+// FollowRel fetches the document linked to from doc by the first link with
+// the relation type rel, using the link's method.
+func (c *Client) FollowRel(ctx context.Context, doc *Document, rel string) (*Document, error) {
+	link, ok := doc.LinkRel(rel)
+	if !ok {
+		return nil, fmt.Errorf("%w: rel %q", ErrNoLink, rel)
+	}
+	return c.FollowLink(ctx, doc, link)
+}
+
+// FollowTemplate fetches the document linked to from doc by the link
+// template labelled label, expanded with values as by
+// [hmc.LinkTemplate.Expand], using the template's method.
+func (c *Client) FollowTemplate(ctx context.Context, doc *Document, label string, values map[string]any) (*Document, error) {
+	tmpl, ok := doc.LinkTemplate(label)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoLink, label)
+	}
+	link, err := tmpl.Link(values)
+	if err != nil {
+		return nil, fmt.Errorf("expanding href of %q: %w", label, err)
+	}
+	return c.FollowLink(ctx, doc, link)
+}
+
+// FollowLink fetches the document that link, which was found in doc, links
+// to, using the link's method.
+func (c *Client) FollowLink(ctx context.Context, doc *Document, link hmc.Link) (*Document, error) {
+	href, err := url.Parse(link.Href)
+	if err != nil {
+		return nil, fmt.Errorf("parsing href of %q: %w", link.Label, err)
+	}
+	method := strings.ToUpper(cmp.Or(link.Method, http.MethodGet))
+	req, err := http.NewRequestWithContext(ctx, method, doc.URL.ResolveReference(href).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Submit submits the current values of form to its action, using its
+// method and enctype, and returns the resulting document.
+//
+// If the form has any [Form.Submits], it is submitted with the first of
+// them, as a browser does when enter is pressed in a form. Use
+// [Client.SubmitWith] to choose another.
+//
+// As in HTML, a GET form puts its values in the query string of the
+// action, replacing any that are already there.
+func (c *Client) Submit(ctx context.Context, form *Form) (*Document, error) {
+	if len(form.Submits) > 0 {
+		return c.SubmitWith(ctx, form, form.Submits[0])
+	}
+	return c.submit(ctx, form, form.Method, form.Action, form.Values())
+}
+
+// SubmitWith submits form as [Client.Submit] does, but as if with the
+// submit button s, which should be one of [Form.Submits]. The Name and
+// Value of s are added to the submission, and its Method and Action
+// override the form's.
+func (c *Client) SubmitWith(ctx context.Context, form *Form, s hmc.Submit) (*Document, error) {
+	values := form.Values()
+	if s.Name != "" {
+		values.Add(s.Name, s.Value)
+	}
+	return c.submit(ctx, form, cmp.Or(s.Method, form.Method), cmp.Or(s.Action, form.Action), values)
+}
+
+func (c *Client) submit(ctx context.Context, form *Form, method, action string, values url.Values) (*Document, error) {
+	u, err := hmc.Form[struct{}]{Action: action}.ResolveAction(form.base)
+	if err != nil {
+		return nil, fmt.Errorf("resolving form action: %w", err)
+	}
+
+	method = strings.ToUpper(cmp.Or(method, http.MethodGet))
+
+	if method == http.MethodGet {
+		if len(form.attachments) > 0 {
+			return nil, fmt.Errorf("%w: files can't be submitted with method GET", ErrUnsupportedMediaType)
+		}
+		u.RawQuery = values.Encode()
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return c.Do(req)
+	}
+
+	body, contentType, err := encodeBody(form.Enctype, values, form.attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(req)
+}
+
+// Options fetches the page of options of s that match search from
+// [hmc.Select.Source], counting pages from 1. s should be one of the
+// controls of form.
+func (c *Client) Options(ctx context.Context, form *Form, s *hmc.Select, search string, page int) (hmc.OptionList, error) {
+	if s.Source == nil {
+		return hmc.OptionList{}, fmt.Errorf("%q has no option source", s.Name)
+	}
+	u, err := s.Source.URL(form.base, search, page)
+	if err != nil {
+		return hmc.OptionList{}, fmt.Errorf("resolving option source of %q: %w", s.Name, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return hmc.OptionList{}, err
+	}
+	doc, err := c.Do(req)
+	if err != nil {
+		return hmc.OptionList{}, err
+	}
+	var list hmc.OptionList
+	if err := xml.Unmarshal(doc.Body, &list); err != nil {
+		return hmc.OptionList{}, fmt.Errorf("parsing options of %q: %w", s.Name, err)
+	}
+	return list, nil
+}
+
+// encodeBody encodes values, and the files attached to inputs by name, as
+// enctype. Only a multipart body can hold files.
+func encodeBody(enctype string, values url.Values, files map[string][]Attachment) (io.Reader, string, error) {
+	if len(files) > 0 && enctype != hmc.EnctypeMultipart {
+		return nil, "", fmt.Errorf("%w: files can't be submitted with form enctype %q", ErrUnsupportedMediaType, enctype)
+	}
+	switch enctype {
+	case "", hmc.EnctypeURLEncoded:
+		return strings.NewReader(values.Encode()), hmc.EnctypeURLEncoded, nil
+	case hmc.EnctypeMultipart:
+		buf := bytes.Buffer{}
+		w := multipart.NewWriter(&buf)
+		for name, vs := range values {
+			for _, v := range vs {
+				if err := w.WriteField(name, v); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(files)) {
+			for _, f := range files[name] {
+				if err := writeFile(w, name, f); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, w.FormDataContentType(), nil
+	case hmc.EnctypeJSON:
+		object := make(map[string]any, len(values))
+		for name, vs := range values {
+			if len(vs) == 1 {
+				object[name] = vs[0]
+			} else {
+				object[name] = vs
+			}
+		}
+		data, err := json.Marshal(object)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), hmc.EnctypeJSON, nil
+	default:
+		return nil, "", fmt.Errorf("%w: form enctype %q", ErrUnsupportedMediaType, enctype)
+	}
+}
+
+// writeFile writes the file f as a part of w named name.
+func writeFile(w *multipart.Writer, name string, f Attachment) error {
+	contentType := cmp.Or(f.ContentType, mime.TypeByExtension(path.Ext(f.Filename)), "application/octet-stream")
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name, "filename": f.Filename}))
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(f.Content)
+	return err
+}
+
+// Do sends req, asking for XML, and parses the response.
+//
+// Responses with an error status are still parsed, since a server will
+// often respond to an invalid submission by returning the form again with
+// its errors.
+func (c *Client) Do(req *http.Request) (*Document, error) {
+	req.Header.Set("Accept", accept)
+
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedMediaType, err)
+	}
+	if mediaType != "application/xml" && mediaType != "text/xml" && !strings.HasSuffix(mediaType, "+xml") {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+
+	doc := &Document{
+		URL:        res.Request.URL,
+		StatusCode: res.StatusCode,
+		Body:       body,
+	}
+	if err := parse(doc, bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	return doc, nil
+}
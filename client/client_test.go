@@ -0,0 +1,691 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/client"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type loginPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"loginPage"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[login]
+	Home    hmc.Link
+}
+
+type login struct {
+	Username hmc.Input
+	Password hmc.Input
+	Remember hmc.Select
+	Misc     hmc.Map
+	Forgot   hmc.Link
+}
+
+func newLoginPage() loginPage {
+	return loginPage{
+		Namespace: hmc.SetNamespace(),
+		Form: hmc.Form[login]{
+			Method: "POST",
+			Action: "session",
+			Elements: login{
+				Username: hmc.Input{Label: "Username", Name: "username", Required: true},
+				Password: hmc.Input{Label: "Password", Name: "password", Type: "password", Required: true},
+				Remember: hmc.Select{Label: "Remember me", Name: "remember", Options: []hmc.Option{
+					{Value: "yes"}, {Value: "no", Selected: true},
+				}},
+				Misc:   hmc.Map{Label: "Misc", Name: "misc"},
+				Forgot: hmc.Link{Label: "Forgot password", Href: "forgot"},
+			},
+		},
+		Home: hmc.Link{Label: "Home", Href: "/"},
+	}
+}
+
+func writeXml(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts/login", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, newLoginPage())
+	})
+	mux.HandleFunc("POST /accounts/session", func(w http.ResponseWriter, r *http.Request) {
+		page := newLoginPage()
+		err := r.ParseForm()
+		assert.FatalErr(t, "parsing submitted form", err)
+		page.Form.ExtractFormValue(r.PostForm)
+		if ok, _ := page.Form.Validate(); !ok {
+			writeXml(w, http.StatusUnprocessableEntity, page)
+			return
+		}
+		page.Message = "Welcome " + page.Form.Elements.Username.Value +
+			", remember=" + page.Form.Elements.Remember.Value() +
+			", colour=" + page.Form.Elements.Misc.Entries["colour"][0]
+		writeXml(w, http.StatusOK, page)
+	})
+	mux.HandleFunc("GET /accounts/forgot", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"forgot"`
+		}{})
+	})
+	mux.HandleFunc("GET /json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGetParsesControls(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	c := client.Client{}
+	doc, err := c.Get(context.Background(), srv.URL+"/accounts/login")
+	assert.FatalErr(t, "getting", err)
+
+	assert.Eq(t, "status", http.StatusOK, doc.StatusCode)
+	assert.Eq(t, "one form", 1, len(doc.Forms))
+	assert.Eq(t, "links outside forms", 1, len(doc.Links))
+	assert.Eq(t, "home link", hmc.Link{Label: "Home", Href: "/"}, doc.Links[0])
+
+	form := doc.Forms[0]
+	assert.Eq(t, "method", "POST", form.Method)
+	assert.Eq(t, "action", "session", form.Action)
+	assert.Eq(t, "controls", 4, len(form.Controls))
+	assert.Eq(t, "links inside form", 1, len(form.Links))
+
+	action, err := form.ResolveAction()
+	assert.FatalErr(t, "resolving action", err)
+	assert.Eq(t, "resolved action", srv.URL+"/accounts/session", action.String())
+}
+
+func TestFollow(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	c := client.Client{}
+	doc, err := c.Get(context.Background(), srv.URL+"/accounts/login")
+	assert.FatalErr(t, "getting", err)
+
+	next, err := c.Follow(context.Background(), doc, "Forgot password")
+	assert.FatalErr(t, "following link in form", err)
+	assert.Eq(t, "followed url", srv.URL+"/accounts/forgot", next.URL.String())
+
+	_, err = c.Follow(context.Background(), doc, "Nowhere")
+	assert.FatalErrIs(t, "following missing link", err, client.ErrNoLink)
+}
+
+func TestSubmit(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	c := client.Client{}
+	doc, err := c.Get(context.Background(), srv.URL+"/accounts/login")
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	assert.FatalErr(t, "setting username", form.Set("username", "john"))
+	assert.FatalErr(t, "setting select", form.Set("remember", "yes"))
+	assert.FatalErr(t, "setting map entry", form.Set("misc[colour]", "blue"))
+	assert.FatalErrIs(t, "setting unknown control", form.Set("nope", "x"), client.ErrNoControl)
+
+	doc, err = c.Submit(context.Background(), form)
+	assert.FatalErr(t, "submitting invalid form", err)
+	assert.Eq(t, "invalid status", http.StatusUnprocessableEntity, doc.StatusCode)
+	password := doc.Forms[0].Controls[1].(*hmc.Input)
+	assert.Eq(t, "error is returned", `"password" is required`, password.FirstError())
+
+	form = doc.Forms[0]
+	assert.FatalErr(t, "setting password", form.Set("password", "hunter2"))
+	assert.FatalErr(t, "setting map entry", form.Set("misc[colour]", "blue"))
+
+	doc, err = c.Submit(context.Background(), form)
+	assert.FatalErr(t, "submitting", err)
+	assert.Eq(t, "status", http.StatusOK, doc.StatusCode)
+
+	var page struct {
+		Message string
+	}
+	err = xml.Unmarshal(doc.Body, &page)
+	assert.FatalErr(t, "unmarshalling body", err)
+	assert.Eq(t, "message", "Welcome john, remember=yes, colour=blue", page.Message)
+}
+
+func TestGetRejectsJson(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	c := client.Client{}
+	_, err := c.Get(context.Background(), srv.URL+"/json")
+	if !errors.Is(err, client.ErrUnsupportedMediaType) {
+		t.Fatalf("expected ErrUnsupportedMediaType, got %v", err)
+	}
+}
+
+type orderPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"orderPage"`
+	Form    hmc.Form[order]
+}
+
+type order struct {
+	Customer hmc.Select
+}
+
+func TestOptions(t *testing.T) {
+	source := hmc.OptionSource{Href: "/customers"}
+	customers := []hmc.Option{
+		{Label: "Acme Ltd", Value: "c-1"},
+		{Label: "Bakery Co", Value: "c-2"},
+		{Label: "Acme Holdings", Value: "c-3"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/new", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, orderPage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[order]{
+				Elements: order{
+					Customer: hmc.Select{Label: "Customer", Name: "customer", Exhaustive: true, Source: &source},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("GET /customers", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, source.List(r.URL, customers, 1))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	doc, err := c.Get(context.Background(), srv.URL+"/orders/new")
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	s := form.Controls[0].(*hmc.Select)
+	assert.Eq(t, "source", source, *s.Source)
+
+	list, err := c.Options(context.Background(), form, s, "acme", 2)
+	assert.FatalErr(t, "fetching options", err)
+	assert.Eq(t, "total", 2, list.Total)
+	assert.SlicesEq(t, "options", []hmc.Option{{Label: "Acme Holdings", Value: "c-3"}}, list.Options)
+	assert.Eq(t, "prev", "/customers?q=acme", list.Prev)
+}
+
+type line struct {
+	SKU hmc.Input
+}
+
+type cartPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"cartPage"`
+	Form    hmc.Form[hmc.List[line]]
+}
+
+func TestGetListItems(t *testing.T) {
+	page := cartPage{
+		Namespace: hmc.SetNamespace(),
+		Form: hmc.Form[hmc.List[line]]{
+			Elements: hmc.List[line]{
+				Name:      "lines",
+				Prototype: line{SKU: hmc.Input{Name: "sku"}},
+			},
+		},
+	}
+	page.Form.Elements.Add().SKU.Value = "A1"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, page)
+	}))
+	defer srv.Close()
+
+	c := client.Client{}
+	doc, err := c.Get(context.Background(), srv.URL)
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	assert.Eq(t, "prototype is left out", 1, len(form.Controls))
+	assert.Eq(t, "item control", "lines[0][sku]", form.Controls[0].(*hmc.Input).Name)
+	assert.FatalErr(t, "setting item control", form.Set("lines[0][sku]", "B2"))
+	assert.Eq(t, "values", "lines%5B0%5D%5Bsku%5D=B2", form.Values().Encode())
+}
+
+type post struct {
+	Title   hmc.Input
+	Publish hmc.Submit
+	Draft   hmc.Submit
+	Delete  hmc.Submit
+}
+
+type postPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"postPage"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[post]
+}
+
+func TestSubmitWith(t *testing.T) {
+	newPostPage := func() postPage {
+		return postPage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[post]{
+				Method: "POST",
+				Elements: post{
+					Title:   hmc.Input{Label: "Title", Name: "title"},
+					Publish: hmc.Submit{Label: "Publish", Name: "action", Value: "publish"},
+					Draft:   hmc.Submit{Label: "Save as draft", Name: "action", Value: "draft"},
+					Delete:  hmc.Submit{Label: "Delete", Method: "DELETE", Action: "/posts/1"},
+				},
+			},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /posts/new", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, newPostPage())
+	})
+	mux.HandleFunc("POST /posts/new", func(w http.ResponseWriter, r *http.Request) {
+		page := newPostPage()
+		_ = r.ParseForm()
+		page.Form.ExtractFormValue(r.PostForm)
+		if s, ok := page.Form.SubmittedBy(); ok {
+			page.Message = s.Value + " " + page.Form.Elements.Title.Value
+		}
+		writeXml(w, http.StatusOK, page)
+	})
+	mux.HandleFunc("DELETE /posts/1", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"deleted"`
+		}{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	ctx := context.Background()
+	doc, err := c.Get(ctx, srv.URL+"/posts/new")
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	assert.Eq(t, "submits", 3, len(form.Submits))
+	assert.FatalErr(t, "setting title", form.Set("title", "Hello"))
+
+	doc, err = c.Submit(ctx, form)
+	assert.FatalErr(t, "submitting", err)
+	assert.True(t, "submitted with the first submit by default", bytes.Contains(doc.Body, []byte("<Message>publish Hello</Message>")))
+
+	draft, ok := form.Submit("Save as draft")
+	assert.True(t, "found draft submit", ok)
+	doc, err = c.SubmitWith(ctx, form, draft)
+	assert.FatalErr(t, "submitting draft", err)
+	assert.True(t, "submitted with draft", bytes.Contains(doc.Body, []byte("<Message>draft Hello</Message>")))
+
+	del, _ := form.Submit("Delete")
+	doc, err = c.SubmitWith(ctx, form, del)
+	assert.FatalErr(t, "deleting", err)
+	assert.Eq(t, "method and action are overridden", srv.URL+"/posts/1", doc.URL.String())
+}
+
+type settings struct {
+	Subscribe hmc.Checkbox
+	Theme     hmc.RadioGroup
+}
+
+type settingsPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"settingsPage"`
+	Form    hmc.Form[settings]
+}
+
+func TestSetCheckboxRadioGroup(t *testing.T) {
+	page := settingsPage{
+		Namespace: hmc.SetNamespace(),
+		Form: hmc.Form[settings]{
+			Elements: settings{
+				Subscribe: hmc.Checkbox{Label: "Subscribe", Name: "subscribe", Value: "yes", Checked: true},
+				Theme: hmc.RadioGroup{Label: "Theme", Name: "theme", Options: []hmc.Option{
+					{Value: "light", Selected: true}, {Value: "dark"},
+				}},
+			},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, page)
+	}))
+	defer srv.Close()
+
+	c := client.Client{}
+	doc, err := c.Get(context.Background(), srv.URL)
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	assert.Eq(t, "controls", 2, len(form.Controls))
+	assert.Eq(t, "initial values", "subscribe=yes&theme=light", form.Values().Encode())
+
+	assert.FatalErr(t, "unchecking", form.Set("subscribe"))
+	assert.FatalErr(t, "choosing", form.Set("theme", "dark"))
+	assert.Eq(t, "unchecked is absent", "theme=dark", form.Values().Encode())
+
+	assert.True(t, "checking with another value", form.Set("subscribe", "on") != nil)
+	assert.True(t, "choosing an unknown option", form.Set("theme", "blue") != nil)
+}
+
+type guestbook struct {
+	CSRF    hmc.CSRFToken
+	Message hmc.Input
+}
+
+type guestbookPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"guestbookPage"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[guestbook]
+}
+
+func TestSubmitCSRFToken(t *testing.T) {
+	csrf := hmc.CSRF{Secret: []byte("0123456789abcdef0123456789abcdef")}
+	newGuestbookPage := func(session string) guestbookPage {
+		p := guestbookPage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[guestbook]{
+				Method:   "POST",
+				Elements: guestbook{Message: hmc.Input{Label: "Message", Name: "message"}},
+			},
+		}
+		if err := csrf.Issue(session, &p.Form); err != nil {
+			t.Error(err)
+		}
+		return p
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /guestbook", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, newGuestbookPage(r.URL.Query().Get("session")))
+	})
+	mux.HandleFunc("POST /guestbook", func(w http.ResponseWriter, r *http.Request) {
+		p := newGuestbookPage(r.URL.Query().Get("session"))
+		_ = r.ParseForm()
+		p.Form.ExtractFormValue(r.PostForm)
+		if err := p.Form.Elements.CSRF.Err(); err != nil {
+			writeXml(w, http.StatusForbidden, p)
+			return
+		}
+		p.Message = "thanks"
+		writeXml(w, http.StatusOK, p)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	ctx := context.Background()
+	doc, err := c.Get(ctx, srv.URL+"/guestbook?session=a")
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	hidden, ok := form.Controls[0].(*hmc.Hidden)
+	assert.True(t, "token is a hidden control", ok)
+	token, err := csrf.Token("a")
+	assert.FatalErr(t, "making token", err)
+	assert.Eq(t, "token", token, hidden.Value)
+
+	doc, err = c.Submit(ctx, form)
+	assert.FatalErr(t, "submitting", err)
+	assert.Eq(t, "token is accepted", http.StatusOK, doc.StatusCode)
+
+	form.Action = "?session=b"
+	doc, err = c.Submit(ctx, form)
+	assert.FatalErr(t, "submitting to another session", err)
+	assert.Eq(t, "token is refused", http.StatusForbidden, doc.StatusCode)
+}
+
+type attachment struct {
+	Title hmc.Input
+	File  hmc.File
+}
+
+type attachmentPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"attachmentPage"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[attachment]
+}
+
+func TestSubmitAttachment(t *testing.T) {
+	newAttachmentPage := func() attachmentPage {
+		return attachmentPage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[attachment]{
+				Method:  "POST",
+				Enctype: hmc.EnctypeMultipart,
+				Elements: attachment{
+					Title: hmc.Input{Label: "Title", Name: "title"},
+					File:  hmc.File{Label: "File", Name: "file", Required: true, Accept: []string{"text/plain"}},
+				},
+			},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, newAttachmentPage())
+	})
+	mux.HandleFunc("POST /attachments", func(w http.ResponseWriter, r *http.Request) {
+		p := newAttachmentPage()
+		err := r.ParseMultipartForm(1 << 20)
+		assert.FatalErr(t, "parsing submitted form", err)
+		p.Form.ExtractMultipartForm(r.MultipartForm)
+		if ok, _ := p.Form.Validate(); !ok {
+			writeXml(w, http.StatusUnprocessableEntity, p)
+			return
+		}
+		f, err := p.Form.Elements.File.Files[0].Open()
+		assert.FatalErr(t, "opening file", err)
+		defer f.Close()
+		content, err := io.ReadAll(f)
+		assert.FatalErr(t, "reading file", err)
+		p.Message = p.Form.Elements.Title.Value + ": " + p.Form.Elements.File.Files[0].Filename + " " + string(content)
+		writeXml(w, http.StatusOK, p)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	ctx := context.Background()
+	doc, err := c.Get(ctx, srv.URL+"/attachments")
+	assert.FatalErr(t, "getting", err)
+
+	form := doc.Forms[0]
+	_, ok := form.Controls[1].(*hmc.File)
+	assert.True(t, "file input is a file", ok)
+	assert.True(t, "file input can't be set", form.Set("file", "notes.txt") != nil)
+	assert.True(t, "only one file", form.Attach("file", client.Attachment{}, client.Attachment{}) != nil)
+	assert.True(t, "no such file input", errors.Is(form.Attach("title", client.Attachment{}), client.ErrNoControl))
+
+	doc, err = c.Submit(ctx, form)
+	assert.FatalErr(t, "submitting without a file", err)
+	assert.Eq(t, "file is required", http.StatusUnprocessableEntity, doc.StatusCode)
+
+	form = doc.Forms[0]
+	err = form.Set("title", "Notes")
+	assert.FatalErr(t, "setting title", err)
+	err = form.Attach("file", client.Attachment{Filename: "notes.txt", Content: []byte("hello")})
+	assert.FatalErr(t, "attaching", err)
+	doc, err = c.Submit(ctx, form)
+	assert.FatalErr(t, "submitting", err)
+	assert.Eq(t, "file is accepted", http.StatusOK, doc.StatusCode)
+	assert.True(t, "file is uploaded", bytes.Contains(doc.Body, []byte("<Message>Notes: notes.txt hello</Message>")))
+
+	form.Enctype = hmc.EnctypeURLEncoded
+	_, err = c.Submit(ctx, form)
+	assert.True(t, "files need a multipart form", errors.Is(err, client.ErrUnsupportedMediaType))
+}
+
+func TestFollowRel(t *testing.T) {
+	type article struct {
+		hmc.Namespace
+		XMLName xml.Name `xml:"article"`
+		Links   []hmc.Link
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /articles/1", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, article{
+			Namespace: hmc.SetNamespace(),
+			Links: []hmc.Link{
+				{Label: "Article 1", Href: "/articles/1", Rel: "self"},
+				{Label: "Article 2", Href: "/articles/2", Rel: "Next"},
+				{Label: "Archive", Href: "/articles/1/archive", Rel: "archive", Method: "POST"},
+			},
+		})
+	})
+	mux.HandleFunc("GET /articles/2", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"second"`
+		}{})
+	})
+	mux.HandleFunc("POST /articles/1/archive", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"archived"`
+		}{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	ctx := context.Background()
+	doc, err := c.Get(ctx, srv.URL+"/articles/1")
+	assert.FatalErr(t, "getting", err)
+
+	self, ok := doc.LinkRel("self")
+	assert.True(t, "found self", ok)
+	assert.Eq(t, "self", "/articles/1", self.Href)
+
+	next, err := c.FollowRel(ctx, doc, "next")
+	assert.FatalErr(t, "following next", err)
+	assert.Eq(t, "next", srv.URL+"/articles/2", next.URL.String())
+
+	archived, err := c.FollowRel(ctx, doc, "archive")
+	assert.FatalErr(t, "following archive", err)
+	assert.True(t, "followed with the link's method", bytes.Contains(archived.Body, []byte("<archived>")))
+
+	_, err = c.FollowRel(ctx, doc, "prev")
+	assert.FatalErrIs(t, "following missing rel", err, client.ErrNoLink)
+}
+
+func TestFollowTemplate(t *testing.T) {
+	type orders struct {
+		hmc.Namespace
+		XMLName xml.Name `xml:"orders"`
+		Order   hmc.LinkTemplate
+		Search  hmc.LinkTemplate
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != "" {
+			writeXml(w, http.StatusOK, struct {
+				XMLName xml.Name `xml:"results"`
+				Query   string   `xml:"query,attr"`
+			}{Query: q})
+			return
+		}
+		writeXml(w, http.StatusOK, orders{
+			Namespace: hmc.SetNamespace(),
+			Order: hmc.LinkTemplate{
+				Label:     "Order",
+				Href:      "/orders/{id}",
+				Variables: []hmc.TemplateVariable{{Label: "Order ID", Name: "id", Required: true}},
+			},
+			Search: hmc.LinkTemplate{
+				Label:     "Search",
+				Href:      "/orders{?q}",
+				Rel:       "search",
+				Variables: []hmc.TemplateVariable{{Label: "Query", Name: "q"}},
+			},
+		})
+	})
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"order"`
+			ID      string   `xml:"id,attr"`
+		}{ID: r.PathValue("id")})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	ctx := context.Background()
+	doc, err := c.Get(ctx, srv.URL+"/orders")
+	assert.FatalErr(t, "getting", err)
+	assert.Eq(t, "link templates", 2, len(doc.LinkTemplates))
+	assert.Eq(t, "order href", "/orders/{id}", doc.LinkTemplates[0].Href)
+	assert.Eq(t, "order variables", 1, len(doc.LinkTemplates[0].Variables))
+
+	order, err := c.FollowTemplate(ctx, doc, "Order", map[string]any{"id": 7})
+	assert.FatalErr(t, "following order", err)
+	assert.Eq(t, "order", srv.URL+"/orders/7", order.URL.String())
+	assert.True(t, "order body", bytes.Contains(order.Body, []byte(`id="7"`)))
+
+	results, err := c.FollowTemplate(ctx, doc, "Search", map[string]any{"q": "blue & green"})
+	assert.FatalErr(t, "following search", err)
+	assert.True(t, "search body", bytes.Contains(results.Body, []byte(`query="blue &amp; green"`)))
+
+	_, err = c.FollowTemplate(ctx, doc, "Order", nil)
+	assert.FatalErrIs(t, "following without id", err, hmc.ErrTemplateVariableMissing)
+
+	_, err = c.FollowTemplate(ctx, doc, "Customer", nil)
+	assert.FatalErrIs(t, "following missing template", err, client.ErrNoLink)
+}
+
+func TestFollowPagination(t *testing.T) {
+	type orders struct {
+		hmc.Namespace
+		XMLName    xml.Name `xml:"orders"`
+		Page       int      `xml:"page,attr"`
+		Pagination hmc.Pagination
+	}
+
+	pager := hmc.Pager{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		page := pager.ParsePage(r.URL)
+		writeXml(w, http.StatusOK, orders{
+			Namespace:  hmc.SetNamespace(),
+			Page:       page,
+			Pagination: pager.Pages(r.URL, page, 10, 25),
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := client.Client{}
+	ctx := context.Background()
+	doc, err := c.Get(ctx, srv.URL+"/orders?status=paid")
+	assert.FatalErr(t, "getting", err)
+	assert.Eq(t, "pagination links", 3, len(doc.Links))
+
+	doc, err = c.FollowRel(ctx, doc, "next")
+	assert.FatalErr(t, "following next", err)
+	assert.True(t, "second page", bytes.Contains(doc.Body, []byte(`page="2"`)))
+
+	doc, err = c.FollowRel(ctx, doc, "last")
+	assert.FatalErr(t, "following last", err)
+	assert.Eq(t, "last", srv.URL+"/orders?page=3&status=paid", doc.URL.String())
+
+	_, err = c.FollowRel(ctx, doc, "next")
+	assert.FatalErrIs(t, "following past last", err, client.ErrNoLink)
+}
@@ -0,0 +1,430 @@
+package client
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+
+	"github.com/Teajey/hmc"
+)
+
+// ErrNoControl is returned when setting the value of a control that the
+// form doesn't have.
+var ErrNoControl = errors.New("no such control")
+
+// Document is a parsed hmc resource.
+type Document struct {
+	// URL is where the document was fetched from. Relative links and form
+	// actions are resolved against it.
+	URL        *url.URL
+	StatusCode int
+	// Body is the raw XML of the document.
+	Body []byte
+	// Forms are all of the c:Form elements in the document, in document
+	// order.
+	Forms []*Form
+	// Links are the c:Link elements in the document that are outside of any
+	// form, in document order.
+	Links []hmc.Link
+	// LinkTemplates are the c:LinkTemplate elements in the document that
+	// are outside of any form, in document order.
+	LinkTemplates []hmc.LinkTemplate
+}
+
+// Link returns the first link in the document labelled label, looking in
+// [Document.Links] and then in the links of each form.
+func (d *Document) Link(label string) (hmc.Link, bool) {
+	for _, l := range d.Links {
+		if l.Label == label {
+			return l, true
+		}
+	}
+	for _, f := range d.Forms {
+		for _, l := range f.Links {
+			if l.Label == label {
+				return l, true
+			}
+		}
+	}
+	return hmc.Link{}, false
+}
+
+// LinkRel returns the first link in the document with the relation type
+// rel, looking in the same order as [Document.Link].
+func (d *Document) LinkRel(rel string) (hmc.Link, bool) {
+	for _, l := range d.Links {
+		if l.HasRel(rel) {
+			return l, true
+		}
+	}
+	for _, f := range d.Forms {
+		for _, l := range f.Links {
+			if l.HasRel(rel) {
+				return l, true
+			}
+		}
+	}
+	return hmc.Link{}, false
+}
+
+// LinkTemplate returns the first link template in the document labelled
+// label, looking in [Document.LinkTemplates] and then in the link templates
+// of each form.
+func (d *Document) LinkTemplate(label string) (hmc.LinkTemplate, bool) {
+	for _, l := range d.LinkTemplates {
+		if l.Label == label {
+			return l, true
+		}
+	}
+	for _, f := range d.Forms {
+		for _, l := range f.LinkTemplates {
+			if l.Label == label {
+				return l, true
+			}
+		}
+	}
+	return hmc.LinkTemplate{}, false
+}
+
+// Form is a c:Form found in a [Document].
+type Form struct {
+	Method  string
+	Action  string
+	Enctype string
+	// Controls are the inputs of the form in document order. Each is one of
+	// *[hmc.Input], *[hmc.File], *[hmc.Textarea], *[hmc.Select],
+	// *[hmc.Checkbox], *[hmc.RadioGroup], *[hmc.Map] or *[hmc.Hidden].
+	// Hidden controls, including CSRF tokens, are submitted as they are
+	// unless they are Set. Files are given to a File with [Form.Attach].
+	Controls []any
+	// Links are the c:Link elements inside the form.
+	Links []hmc.Link
+	// LinkTemplates are the c:LinkTemplate elements inside the form.
+	LinkTemplates []hmc.LinkTemplate
+	// Submits are the c:Submit elements inside the form, in document order.
+	// The first is the form's default, as in HTML.
+	Submits []hmc.Submit
+
+	base        *url.URL
+	attachments map[string][]Attachment
+}
+
+// Attachment is a file to upload with a c:Input of type "file".
+type Attachment struct {
+	Filename string
+	// ContentType is the MIME type of Content. If empty, it is guessed from
+	// the extension of Filename.
+	ContentType string
+	Content     []byte
+}
+
+// ResolveAction resolves [Form.Action] against the URL of the document the
+// form was found in.
+func (f *Form) ResolveAction() (*url.URL, error) {
+	return hmc.Form[struct{}]{Action: f.Action}.ResolveAction(f.base)
+}
+
+// Submit returns the first of [Form.Submits] labelled label.
+func (f *Form) Submit(label string) (hmc.Submit, bool) {
+	for _, s := range f.Submits {
+		if s.Label == label {
+			return s, true
+		}
+	}
+	return hmc.Submit{}, false
+}
+
+// Attach sets the files to upload with the c:Input of type "file" named
+// name, replacing any that were attached before. Attaching no files
+// removes them. [ErrNoControl] is returned if the form has no such input.
+//
+// Files can only be submitted by a form with an Enctype of
+// [hmc.EnctypeMultipart].
+func (f *Form) Attach(name string, files ...Attachment) error {
+	for _, c := range f.Controls {
+		c, ok := c.(*hmc.File)
+		if !ok || c.Name != name {
+			continue
+		}
+		if !c.Multiple && len(files) > 1 {
+			return fmt.Errorf("%q takes at most one file", name)
+		}
+		if f.attachments == nil {
+			f.attachments = map[string][]Attachment{}
+		}
+		f.attachments[name] = files
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrNoControl, name)
+}
+
+// Attachments returns the files attached to the input named name with
+// [Form.Attach].
+func (f *Form) Attachments(name string) []Attachment {
+	return f.attachments[name]
+}
+
+// Set sets the value of the control named name.
+//
+// A c:Checkbox is checked by setting it to its
+// [hmc.Checkbox.SubmittedValue], and unchecked by setting it to no values.
+//
+// A name like "foo[bar]" sets the entry "bar" of the c:Map named "foo", and
+// one like "foo[bar][baz]" sets a nested entry of it. If
+// no other control matches name, it is set on a c:Map without a name,
+// if the form has one. Otherwise [ErrNoControl] is returned.
+func (f *Form) Set(name string, values ...string) error {
+	for _, c := range f.Controls {
+		switch c := c.(type) {
+		case *hmc.Input:
+			if c.Name != name {
+				continue
+			}
+			if len(values) != 1 {
+				return fmt.Errorf("%q takes exactly one value", name)
+			}
+			c.Value = values[0]
+			return nil
+		case *hmc.File:
+			if c.Name != name {
+				continue
+			}
+			return fmt.Errorf("%q takes files, which are given with Attach", name)
+		case *hmc.Textarea:
+			if c.Name != name {
+				continue
+			}
+			if len(values) != 1 {
+				return fmt.Errorf("%q takes exactly one value", name)
+			}
+			c.Value = values[0]
+			return nil
+		case *hmc.Hidden:
+			if c.Name != name {
+				continue
+			}
+			if len(values) != 1 {
+				return fmt.Errorf("%q takes exactly one value", name)
+			}
+			c.Value = values[0]
+			return nil
+		case *hmc.Select:
+			if c.Name != name {
+				continue
+			}
+			if !c.Multiple && len(values) > 1 {
+				return fmt.Errorf("%q takes at most one value", name)
+			}
+			if c.Exhaustive && c.Source == nil {
+				for _, v := range values {
+					if !c.HasOption(v) {
+						return fmt.Errorf("%q is not one of the options for %q", v, name)
+					}
+				}
+			}
+			c.SetValues(values...)
+			return nil
+		case *hmc.Checkbox:
+			if c.Name != name {
+				continue
+			}
+			switch {
+			case len(values) == 0:
+				c.Checked = false
+			case len(values) == 1 && values[0] == c.SubmittedValue():
+				c.Checked = true
+			default:
+				return fmt.Errorf("%q is checked with %q, or unchecked with no value", name, c.SubmittedValue())
+			}
+			return nil
+		case *hmc.RadioGroup:
+			if c.Name != name {
+				continue
+			}
+			if len(values) != 1 {
+				return fmt.Errorf("%q takes exactly one value", name)
+			}
+			if !slices.ContainsFunc(c.Options, func(o hmc.Option) bool { return o.Value == values[0] }) {
+				return fmt.Errorf("%q is not one of the options for %q", values[0], name)
+			}
+			c.SetValue(values[0])
+			return nil
+		}
+	}
+
+	for _, c := range f.Controls {
+		m, ok := c.(*hmc.Map)
+		if !ok {
+			continue
+		}
+		if path, ok := m.Path(name); ok {
+			m.Set(path, values...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrNoControl, name)
+}
+
+// Values returns the current values of the form's controls, ready to be
+// submitted.
+func (f *Form) Values() url.Values {
+	values := url.Values{}
+	for _, c := range f.Controls {
+		switch c := c.(type) {
+		case *hmc.Input:
+			values.Add(c.Name, c.Value)
+		case *hmc.Textarea:
+			values.Add(c.Name, c.Value)
+		case *hmc.Hidden:
+			values.Add(c.Name, c.Value)
+		case *hmc.Select:
+			for v := range c.Values() {
+				values.Add(c.Name, v)
+			}
+		case *hmc.Checkbox:
+			if c.Checked {
+				values.Add(c.Name, c.SubmittedValue())
+			}
+		case *hmc.RadioGroup:
+			if v := c.Value(); v != "" {
+				values.Add(c.Name, v)
+			}
+		case *hmc.Map:
+			for name, vs := range c.All() {
+				for _, v := range vs {
+					values.Add(name, v)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// parse finds the controls in the XML document r.
+func parse(doc *Document, r io.Reader) error {
+	d := xml.NewDecoder(r)
+
+	type openForm struct {
+		form  *Form
+		depth int
+	}
+	var forms []openForm
+	depth := 0
+
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var form *Form
+		if len(forms) > 0 {
+			form = forms[len(forms)-1].form
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			local, ok := hmc.ControlElementName(tok.Name)
+			if !ok {
+				depth++
+				continue
+			}
+			var c any
+			switch local {
+			case "Form":
+				f := &Form{base: doc.URL}
+				for _, a := range tok.Attr {
+					switch a.Name.Local {
+					case "method":
+						f.Method = a.Value
+					case "action":
+						f.Action = a.Value
+					case "enctype":
+						f.Enctype = a.Value
+					}
+				}
+				doc.Forms = append(doc.Forms, f)
+				forms = append(forms, openForm{f, depth})
+				depth++
+				continue
+			case "Link":
+				var l hmc.Link
+				if err := d.DecodeElement(&l, &tok); err != nil {
+					return err
+				}
+				if form != nil {
+					form.Links = append(form.Links, l)
+				} else {
+					doc.Links = append(doc.Links, l)
+				}
+				continue
+			case "LinkTemplate":
+				var l hmc.LinkTemplate
+				if err := d.DecodeElement(&l, &tok); err != nil {
+					return err
+				}
+				if form != nil {
+					form.LinkTemplates = append(form.LinkTemplates, l)
+				} else {
+					doc.LinkTemplates = append(doc.LinkTemplates, l)
+				}
+				continue
+			case "Submit":
+				var s hmc.Submit
+				if err := d.DecodeElement(&s, &tok); err != nil {
+					return err
+				}
+				if form != nil {
+					form.Submits = append(form.Submits, s)
+				}
+				continue
+			case "Prototype":
+				// The controls of a new c:List item are named relative to
+				// the item, so they can't be submitted as they are.
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			case "Input":
+				c = &hmc.Input{}
+				if slices.Contains(tok.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: "file"}) {
+					c = &hmc.File{}
+				}
+			case "Textarea":
+				c = &hmc.Textarea{}
+			case "Select":
+				c = &hmc.Select{}
+			case "Checkbox":
+				c = &hmc.Checkbox{}
+			case "RadioGroup":
+				c = &hmc.RadioGroup{}
+			case "Map":
+				c = &hmc.Map{}
+			case "Hidden":
+				c = &hmc.Hidden{}
+			default:
+				depth++
+				continue
+			}
+			if err := d.DecodeElement(c, &tok); err != nil {
+				return err
+			}
+			// Inputs outside of a form aren't valid, so they are ignored.
+			if form != nil {
+				form.Controls = append(form.Controls, c)
+			}
+		case xml.EndElement:
+			depth--
+			if len(forms) > 0 && forms[len(forms)-1].depth == depth {
+				forms = forms[:len(forms)-1]
+			}
+		}
+	}
+}
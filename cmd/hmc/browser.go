@@ -0,0 +1,777 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/client"
+)
+
+// errEOF is returned when input ends while the browser is waiting for it.
+var errEOF = errors.New("end of input")
+
+const help = `Commands:
+  N        follow link N
+  f N      fill in and submit form N
+  t N      expand and follow link template N
+  g URL    go to URL, relative to the current document
+  r        reload the current document
+  b        go back to the previous document
+  s        show the current document again
+  ?        show this help
+  q        quit
+`
+
+type browser struct {
+	in      *bufio.Scanner
+	out     io.Writer
+	client  client.Client
+	doc     *client.Document
+	history []*client.Document
+}
+
+func newBrowser(in io.Reader, out io.Writer) *browser {
+	return &browser{
+		in:  bufio.NewScanner(in),
+		out: out,
+	}
+}
+
+// run browses from rawURL until the user quits or input ends.
+func (b *browser) run(ctx context.Context, rawURL string) error {
+	doc, err := b.client.Get(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	b.visit(doc)
+
+	for {
+		line, err := b.prompt("> ")
+		if errors.Is(err, errEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		switch cmd {
+		case "":
+			continue
+		case "q":
+			return nil
+		case "?":
+			fmt.Fprint(b.out, help)
+		case "s":
+			b.show()
+		case "r":
+			err = b.get(ctx, b.doc.URL.String(), false)
+		case "b":
+			if len(b.history) == 0 {
+				err = errors.New("no previous document")
+				break
+			}
+			b.doc = b.history[len(b.history)-1]
+			b.history = b.history[:len(b.history)-1]
+			b.show()
+		case "g":
+			err = b.get(ctx, arg, true)
+		case "f":
+			err = b.fill(ctx, arg)
+		case "t":
+			err = b.expand(ctx, arg)
+		default:
+			err = b.follow(ctx, cmd)
+		}
+
+		if errors.Is(err, errEOF) {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(b.out, "error: %s\n", err)
+		}
+	}
+}
+
+// prompt writes p and reads a line of input.
+func (b *browser) prompt(p string) (string, error) {
+	line, err := b.readLine(p)
+	return strings.TrimSpace(line), err
+}
+
+// readLine is like prompt, but keeps the line's leading and trailing
+// spaces.
+func (b *browser) readLine(p string) (string, error) {
+	fmt.Fprint(b.out, p)
+	if !b.in.Scan() {
+		fmt.Fprintln(b.out)
+		if err := b.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errEOF
+	}
+	return b.in.Text(), nil
+}
+
+// visit makes doc the current document and shows it.
+func (b *browser) visit(doc *client.Document) {
+	if b.doc != nil {
+		b.history = append(b.history, b.doc)
+	}
+	b.doc = doc
+	b.show()
+}
+
+func (b *browser) get(ctx context.Context, ref string, push bool) error {
+	u, err := b.resolve(ref)
+	if err != nil {
+		return err
+	}
+	doc, err := b.client.Get(ctx, u.String())
+	if err != nil {
+		return err
+	}
+	if !push {
+		b.doc = doc
+		b.show()
+		return nil
+	}
+	b.visit(doc)
+	return nil
+}
+
+func (b *browser) resolve(ref string) (*url.URL, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	return b.doc.URL.ResolveReference(u), nil
+}
+
+// links lists every link in the current document, in the order they are
+// numbered when shown.
+func (b *browser) links() []hmc.Link {
+	links := slices.Clone(b.doc.Links)
+	for _, f := range b.doc.Forms {
+		links = append(links, f.Links...)
+	}
+	return links
+}
+
+func (b *browser) follow(ctx context.Context, arg string) error {
+	n, err := strconv.Atoi(arg)
+	links := b.links()
+	if err != nil || n < 1 || n > len(links) {
+		return fmt.Errorf("unknown command %q, enter ? for help", arg)
+	}
+	doc, err := b.client.FollowLink(ctx, b.doc, links[n-1])
+	if err != nil {
+		return err
+	}
+	b.visit(doc)
+	return nil
+}
+
+// linkTemplates lists every link template in the current document, in the
+// order they are numbered when shown.
+func (b *browser) linkTemplates() []hmc.LinkTemplate {
+	templates := slices.Clone(b.doc.LinkTemplates)
+	for _, f := range b.doc.Forms {
+		templates = append(templates, f.LinkTemplates...)
+	}
+	return templates
+}
+
+// expand prompts for the value of each variable of link template number
+// arg, then follows the expanded link.
+func (b *browser) expand(ctx context.Context, arg string) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "t"))
+	templates := b.linkTemplates()
+	if err != nil || n < 1 || n > len(templates) {
+		return fmt.Errorf("no link template %q", arg)
+	}
+	tmpl := templates[n-1]
+
+	fmt.Fprintln(b.out, "Leave a variable blank to leave it out.")
+	values := map[string]any{}
+	for _, v := range tmpl.Variables {
+		for {
+			line, err := b.prompt(describe(v.Label, v.Name, "", v.Required) + ": ")
+			if err != nil {
+				return err
+			}
+			if line != "" {
+				values[v.Name] = line
+			} else if v.Required {
+				fmt.Fprintln(b.out, "    ! a value is required")
+				continue
+			}
+			break
+		}
+	}
+
+	link, err := tmpl.Link(values)
+	if err != nil {
+		return err
+	}
+	doc, err := b.client.FollowLink(ctx, b.doc, link)
+	if err != nil {
+		return err
+	}
+	b.visit(doc)
+	return nil
+}
+
+func describeLink(l hmc.Link) string {
+	var sb strings.Builder
+	sb.WriteString(l.Label)
+	if l.Rel != "" {
+		fmt.Fprintf(&sb, " [%s]", l.Rel)
+	}
+	sb.WriteString(" -> ")
+	if l.Method != "" {
+		fmt.Fprintf(&sb, "%s ", strings.ToUpper(l.Method))
+	}
+	sb.WriteString(l.Href)
+	if l.Type != "" {
+		fmt.Fprintf(&sb, " (%s)", l.Type)
+	}
+	return sb.String()
+}
+
+func (b *browser) show() {
+	doc := b.doc
+	fmt.Fprintf(b.out, "\n%s %d %s\n", doc.URL, doc.StatusCode, http.StatusText(doc.StatusCode))
+
+	n := 0
+	if len(doc.Links) > 0 {
+		fmt.Fprintln(b.out, "\nLinks:")
+		for _, l := range doc.Links {
+			n++
+			fmt.Fprintf(b.out, "  [%d] %s\n", n, describeLink(l))
+		}
+	}
+
+	t := 0
+	if len(doc.LinkTemplates) > 0 {
+		fmt.Fprintln(b.out, "\nLink templates:")
+		for _, l := range doc.LinkTemplates {
+			t++
+			fmt.Fprintf(b.out, "  [t%d] %s\n", t, describeLinkTemplate(l))
+		}
+	}
+
+	for i, f := range doc.Forms {
+		fmt.Fprintf(b.out, "\nForm f%d: %s %s\n", i+1, strings.ToUpper(cmp.Or(f.Method, http.MethodGet)), cmp.Or(f.Action, "(this document)"))
+		for _, c := range f.Controls {
+			showControl(b.out, c)
+		}
+		for _, sub := range f.Submits {
+			fmt.Fprintf(b.out, "  <%s>\n", describeSubmit(sub))
+		}
+		for _, l := range f.Links {
+			n++
+			fmt.Fprintf(b.out, "  [%d] %s\n", n, describeLink(l))
+		}
+		for _, l := range f.LinkTemplates {
+			t++
+			fmt.Fprintf(b.out, "  [t%d] %s\n", t, describeLinkTemplate(l))
+		}
+	}
+
+	if n == 0 && t == 0 && len(doc.Forms) == 0 {
+		fmt.Fprintln(b.out, "\nNo links or forms.")
+	}
+	fmt.Fprintln(b.out)
+}
+
+// describeLinkTemplate describes l as [describeLink] does, with its
+// template as its href.
+func describeLinkTemplate(l hmc.LinkTemplate) string {
+	return describeLink(hmc.Link{Label: l.Label, Href: l.Href, Rel: l.Rel, Type: l.Type, Method: l.Method})
+}
+
+func showControl(out io.Writer, c any) {
+	switch c := c.(type) {
+	case *hmc.Input:
+		fmt.Fprintf(out, "  %s: %s\n", describe(c.Label, c.Name, c.Type, c.Required), displayValue(c))
+		showErrors(out, c.Errors)
+	case *hmc.File:
+		fmt.Fprintf(out, "  %s\n", describe(c.Label, c.Name, fileKind(c), c.Required))
+		showErrors(out, c.Errors)
+	case *hmc.Select:
+		kind := "select"
+		if c.Multiple {
+			kind = "multiple"
+		}
+		if c.Exhaustive {
+			kind += ", exhaustive"
+		}
+		if c.Source != nil {
+			kind += ", searchable"
+		}
+		fmt.Fprintf(out, "  %s:\n", describe(c.Label, c.Name, kind, c.Required))
+		listOptions(out, "      ", *c, false)
+		showErrors(out, c.Errors)
+	case *hmc.Textarea:
+		fmt.Fprintf(out, "  %s: %s\n", describe(c.Label, c.Name, "multi-line", c.Required), strconv.Quote(c.Value))
+		showErrors(out, c.Errors)
+	case *hmc.Checkbox:
+		fmt.Fprintf(out, "  %s: %s\n", describe(c.Label, c.Name, "checkbox", c.Required), checkMark(c.Checked))
+		showErrors(out, c.Errors)
+	case *hmc.RadioGroup:
+		fmt.Fprintf(out, "  %s:\n", describe(c.Label, c.Name, "radio", c.Required))
+		listOptions(out, "      ", hmc.Select{Options: c.Options}, false)
+		showErrors(out, c.Errors)
+	case *hmc.Map:
+		fmt.Fprintf(out, "  %s:\n", describe(c.Label, c.NamedKey("..."), "map", false))
+		for name, values := range c.All() {
+			for _, v := range values {
+				fmt.Fprintf(out, "      %s = %s\n", name, v)
+			}
+		}
+		for _, k := range slices.Sorted(maps.Keys(c.KeyErrors)) {
+			showErrors(out, c.KeyErrors[k])
+		}
+		showErrors(out, c.Errors)
+	}
+}
+
+func showErrors(out io.Writer, msgs []string) {
+	for _, msg := range msgs {
+		fmt.Fprintf(out, "    ! %s\n", msg)
+	}
+}
+
+func describe(label, name, kind string, required bool) string {
+	var sb strings.Builder
+	sb.WriteString(cmp.Or(label, name))
+	fmt.Fprintf(&sb, " (%s", name)
+	if kind != "" {
+		fmt.Fprintf(&sb, ", %s", kind)
+	}
+	if required {
+		sb.WriteString(", required")
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// listOptions lists the options of s, each on its own line after indent,
+// with options in groups listed under their group's label. If numbered is
+// set, each option is numbered in the order of [hmc.Select.AllOptions].
+func listOptions(out io.Writer, indent string, s hmc.Select, numbered bool) {
+	n := 0
+	list := func(indent string, options []hmc.Option) {
+		for _, o := range options {
+			n++
+			if numbered {
+				fmt.Fprintf(out, "%s%d) %s\n", indent, n, describeOption(o))
+			} else {
+				fmt.Fprintf(out, "%s%s\n", indent, describeOption(o))
+			}
+		}
+	}
+	list(indent, s.Options)
+	for _, g := range s.Groups {
+		if g.Disabled {
+			fmt.Fprintf(out, "%s%s (disabled):\n", indent, g.Label)
+		} else {
+			fmt.Fprintf(out, "%s%s:\n", indent, g.Label)
+		}
+		list(indent+"  ", g.Options)
+	}
+}
+
+func describeOption(o hmc.Option) string {
+	mark := "( )"
+	if o.Selected {
+		mark = "(*)"
+	}
+	s := fmt.Sprintf("%s %s", mark, cmp.Or(o.Label, o.Value))
+	if o.Label != "" && o.Label != o.Value {
+		s += fmt.Sprintf(" [%s]", o.Value)
+	}
+	if o.Disabled {
+		s += " (disabled)"
+	}
+	return s
+}
+
+func displayValue(i *hmc.Input) string {
+	if i.Type == "password" && i.Value != "" {
+		return "********"
+	}
+	return strconv.Quote(i.Value)
+}
+
+// fill prompts for the value of each control of form number arg, then
+// submits it.
+func (b *browser) fill(ctx context.Context, arg string) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "f"))
+	if err != nil || n < 1 || n > len(b.doc.Forms) {
+		return fmt.Errorf("no form %q", arg)
+	}
+	form := b.doc.Forms[n-1]
+
+	fmt.Fprintln(b.out, "Leave a field blank to keep its current value.")
+	for _, c := range form.Controls {
+		var err error
+		switch c := c.(type) {
+		case *hmc.Input:
+			err = b.fillInput(c)
+		case *hmc.File:
+			err = b.fillFile(form, c)
+		case *hmc.Select:
+			err = b.fillSelect(ctx, form, c)
+		case *hmc.Textarea:
+			err = b.fillTextarea(c)
+		case *hmc.Checkbox:
+			err = b.fillCheckbox(c)
+		case *hmc.RadioGroup:
+			err = b.fillRadioGroup(c)
+		case *hmc.Map:
+			err = b.fillMap(form, c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var doc *client.Document
+	if len(form.Submits) > 1 {
+		sub, err := b.chooseSubmit(form)
+		if err != nil {
+			return err
+		}
+		doc, err = b.client.SubmitWith(ctx, form, sub)
+		if err != nil {
+			return err
+		}
+	} else {
+		doc, err = b.client.Submit(ctx, form)
+		if err != nil {
+			return err
+		}
+	}
+	b.visit(doc)
+	return nil
+}
+
+// chooseSubmit prompts for which of the form's submit buttons to submit it
+// with. The first is chosen by default.
+func (b *browser) chooseSubmit(form *client.Form) (hmc.Submit, error) {
+	for i, sub := range form.Submits {
+		fmt.Fprintf(b.out, "  %d) %s\n", i+1, describeSubmit(sub))
+	}
+	for {
+		line, err := b.prompt("Submit with [1]: ")
+		if err != nil {
+			return hmc.Submit{}, err
+		}
+		if line == "" {
+			return form.Submits[0], nil
+		}
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 1 || n > len(form.Submits) {
+			fmt.Fprintf(b.out, "    ! choose a number from 1 to %d\n", len(form.Submits))
+			continue
+		}
+		return form.Submits[n-1], nil
+	}
+}
+
+func describeSubmit(s hmc.Submit) string {
+	d := cmp.Or(s.Label, s.Value, "Submit")
+	if s.Method != "" {
+		d += " " + strings.ToUpper(s.Method)
+	}
+	if s.Action != "" {
+		d += " -> " + s.Action
+	}
+	return d
+}
+
+func (b *browser) fillInput(i *hmc.Input) error {
+	showErrors(b.out, i.Errors)
+	for {
+		line, err := b.prompt(fmt.Sprintf("%s [%s]: ", describe(i.Label, i.Name, i.Type, i.Required), displayValue(i)))
+		if err != nil {
+			return err
+		}
+		if line != "" {
+			i.Value = line
+		}
+		if i.Required && i.Value == "" {
+			fmt.Fprintln(b.out, "    ! a value is required")
+			continue
+		}
+		return nil
+	}
+}
+
+// fillFile reads the files at the paths given, and attaches them to f.
+func (b *browser) fillFile(form *client.Form, f *hmc.File) error {
+	showErrors(b.out, f.Errors)
+	if f.Multiple {
+		fmt.Fprintln(b.out, "  Enter the paths of the files, separated by commas.")
+	}
+	for {
+		var names []string
+		for _, a := range form.Attachments(f.Name) {
+			names = append(names, a.Filename)
+		}
+		line, err := b.prompt(fmt.Sprintf("%s [%s]: ", describe(f.Label, f.Name, fileKind(f), f.Required), strings.Join(names, ", ")))
+		if err != nil {
+			return err
+		}
+		if line != "" {
+			files, err := readAttachments(line)
+			if err == nil {
+				err = form.Attach(f.Name, files...)
+			}
+			if err != nil {
+				fmt.Fprintf(b.out, "    ! %s\n", err)
+				continue
+			}
+		}
+		if f.Required && len(form.Attachments(f.Name)) == 0 {
+			fmt.Fprintln(b.out, "    ! a file is required")
+			continue
+		}
+		return nil
+	}
+}
+
+// readAttachments reads the files at the comma-separated paths in line.
+func readAttachments(line string) ([]client.Attachment, error) {
+	var files []client.Attachment
+	for p := range strings.SplitSeq(line, ",") {
+		p = strings.TrimSpace(p)
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, client.Attachment{Filename: filepath.Base(p), Content: content})
+	}
+	return files, nil
+}
+
+func fileKind(f *hmc.File) string {
+	kind := "file"
+	if f.Multiple {
+		kind = "files"
+	}
+	if len(f.Accept) > 0 {
+		kind += ", " + strings.Join(f.Accept, ",")
+	}
+	return kind
+}
+
+func (b *browser) fillSelect(ctx context.Context, form *client.Form, s *hmc.Select) error {
+	fmt.Fprintf(b.out, "%s:\n", describe(s.Label, s.Name, "", s.Required))
+	listOptions(b.out, "  ", *s, true)
+	showErrors(b.out, s.Errors)
+	options := slices.Collect(s.AllOptions())
+	if s.Source != nil {
+		fmt.Fprintln(b.out, "  Enter /TEXT to search for more options.")
+	}
+
+	p := "Choose an option by number or value: "
+	if s.Multiple {
+		p = "Choose options by number or value, separated by commas: "
+	}
+
+	for {
+		line, err := b.prompt(p)
+		if err != nil {
+			return err
+		}
+		if search, ok := strings.CutPrefix(line, "/"); ok && s.Source != nil {
+			found, err := b.client.Options(ctx, form, s, strings.TrimSpace(search), 1)
+			if err != nil {
+				fmt.Fprintf(b.out, "    ! %s\n", err)
+				continue
+			}
+			for _, o := range found.Options {
+				options = append(options, o)
+				fmt.Fprintf(b.out, "  %d) %s\n", len(options), describeOption(o))
+			}
+			if found.Total > len(found.Options) {
+				fmt.Fprintf(b.out, "  (%d of %d matches shown)\n", len(found.Options), found.Total)
+			}
+			continue
+		}
+		if line != "" {
+			var values []string
+			for choice := range strings.SplitSeq(line, ",") {
+				choice = strings.TrimSpace(choice)
+				if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(options) {
+					choice = options[n-1].Value
+					// Keep the labels of options found by searching
+					if !s.HasOption(choice) {
+						s.Options = append(s.Options, options[n-1])
+					}
+				}
+				values = append(values, choice)
+			}
+			if !s.Multiple && len(values) > 1 {
+				fmt.Fprintln(b.out, "    ! only one option may be chosen")
+				continue
+			}
+			if unknown := slices.IndexFunc(values, func(v string) bool { return !s.HasOption(v) }); s.Exhaustive && s.Source == nil && unknown >= 0 {
+				fmt.Fprintf(b.out, "    ! %q is not one of the options\n", values[unknown])
+				continue
+			}
+			s.SetValues(values...)
+		}
+		if s.Required && s.Value() == "" {
+			fmt.Fprintln(b.out, "    ! an option is required")
+			continue
+		}
+		return nil
+	}
+}
+
+// fillTextarea reads the lines of a multi-line value up to a line with
+// only "." on it.
+func (b *browser) fillTextarea(t *hmc.Textarea) error {
+	showErrors(b.out, t.Errors)
+	fmt.Fprintf(b.out, "%s [%s]:\n", describe(t.Label, t.Name, "multi-line", t.Required), strconv.Quote(t.Value))
+	fmt.Fprintln(b.out, "  Enter lines of text, then . on a line by itself to finish.")
+	for {
+		var lines []string
+		for {
+			line, err := b.readLine("  | ")
+			if err != nil {
+				return err
+			}
+			if line == "." {
+				break
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) > 0 {
+			t.Value = strings.Join(lines, "\n")
+		}
+		if t.Required && t.Value == "" {
+			fmt.Fprintln(b.out, "    ! a value is required")
+			continue
+		}
+		return nil
+	}
+}
+
+func checkMark(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func (b *browser) fillCheckbox(c *hmc.Checkbox) error {
+	showErrors(b.out, c.Errors)
+	for {
+		line, err := b.prompt(fmt.Sprintf("%s %s (y/n): ", describe(c.Label, c.Name, "checkbox", c.Required), checkMark(c.Checked)))
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(line) {
+		case "":
+		case "y", "yes":
+			c.Checked = true
+		case "n", "no":
+			c.Checked = false
+		default:
+			fmt.Fprintln(b.out, "    ! answer y or n")
+			continue
+		}
+		if c.Required && !c.Checked {
+			fmt.Fprintln(b.out, "    ! this must be checked")
+			continue
+		}
+		return nil
+	}
+}
+
+func (b *browser) fillRadioGroup(r *hmc.RadioGroup) error {
+	fmt.Fprintf(b.out, "%s:\n", describe(r.Label, r.Name, "", r.Required))
+	listOptions(b.out, "  ", hmc.Select{Options: r.Options}, true)
+	showErrors(b.out, r.Errors)
+	for {
+		line, err := b.prompt("Choose an option by number or value: ")
+		if err != nil {
+			return err
+		}
+		if line != "" {
+			choice := strings.TrimSpace(line)
+			if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(r.Options) {
+				choice = r.Options[n-1].Value
+			}
+			if !slices.ContainsFunc(r.Options, func(o hmc.Option) bool { return o.Value == choice }) {
+				fmt.Fprintf(b.out, "    ! %q is not one of the options\n", choice)
+				continue
+			}
+			r.SetValue(choice)
+		}
+		if r.Required && r.Value() == "" {
+			fmt.Fprintln(b.out, "    ! an option is required")
+			continue
+		}
+		return nil
+	}
+}
+
+func (b *browser) fillMap(form *client.Form, m *hmc.Map) error {
+	fmt.Fprintf(b.out, "%s: enter entries as key=value or key[subkey]=value, blank to finish\n", describe(m.Label, m.NamedKey("..."), "map", false))
+	showErrors(b.out, m.Errors)
+	for _, k := range slices.Sorted(maps.Keys(m.KeyErrors)) {
+		showErrors(b.out, m.KeyErrors[k])
+	}
+
+	entries := map[string][]string{}
+	for {
+		line, err := b.prompt("  entry: ")
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			fmt.Fprintln(b.out, "    ! entries must look like key=value")
+			continue
+		}
+		key = strings.TrimSpace(key)
+		entries[key] = append(entries[key], strings.TrimSpace(value))
+	}
+
+	for key, values := range entries {
+		// Nested keys are already in bracket notation
+		first, rest, nested := strings.Cut(key, "[")
+		name := m.NamedKey(first)
+		if nested {
+			name += "[" + rest
+		}
+		if err := form.Set(name, values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type page struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"page"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[signup]
+	About   hmc.Link
+}
+
+type signup struct {
+	Name  hmc.Input
+	Size  hmc.Select
+	Extra hmc.Map
+}
+
+func newPage() page {
+	return page{
+		Namespace: hmc.SetNamespace(),
+		Form: hmc.Form[signup]{
+			Method: "POST",
+			Elements: signup{
+				Name: hmc.Input{Label: "Name", Name: "name", Required: true, MinLength: 3},
+				Size: hmc.Select{Label: "Size", Name: "size", Required: true, Options: []hmc.Option{
+					{Label: "Small", Value: "sm"},
+					{Label: "Large", Value: "lg"},
+				}},
+				Extra: hmc.Map{Label: "Extra", Name: "extra"},
+			},
+		},
+		About: hmc.Link{Label: "About", Href: "/about"},
+	}
+}
+
+func newServer() *httptest.Server {
+	write := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		_ = xml.NewEncoder(w).Encode(v)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /signup", func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, newPage())
+	})
+	mux.HandleFunc("POST /signup", func(w http.ResponseWriter, r *http.Request) {
+		p := newPage()
+		_ = r.ParseForm()
+		p.Form.ExtractFormValue(r.PostForm)
+		if ok, _ := p.Form.Validate(); !ok {
+			write(w, http.StatusUnprocessableEntity, p)
+			return
+		}
+		p.Message = "signed up"
+		write(w, http.StatusOK, p)
+	})
+	mux.HandleFunc("GET /about", func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"about"`
+		}{})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestBrowser(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	in := strings.Join([]string{
+		"1",
+		"b",
+		"f 1",
+		"",      // name is required, so this is refused
+		"Jo",    // too short for the server
+		"2",     // Large
+		"a = b", // map entry
+		"",      // finish map
+		"f 1",   // the form comes back with an error
+		"Joanna",
+		"", // keep Large
+		"", // no more entries
+		"q",
+	}, "\n")
+	out := bytes.Buffer{}
+
+	b := newBrowser(strings.NewReader(in), &out)
+	err := b.run(context.Background(), srv.URL+"/signup")
+	assert.FatalErr(t, "running browser", err)
+
+	output := out.String()
+	for _, expected := range []string{
+		"  [1] About -> /about",
+		srv.URL + "/about 200 OK",
+		"  Size (size, select, required):",
+		"      ( ) Small [sm]",
+		"    ! a value is required",
+		"422 Unprocessable Entity",
+		`    ! "name" requires at least 3 characters (currently 2 characters)`,
+		"      (*) Large [lg]",
+		"      extra[a] = b",
+		srv.URL + "/signup 200 OK",
+	} {
+		assert.True(t, "output contains "+expected, strings.Contains(output, expected))
+	}
+	if t.Failed() {
+		t.Log(output)
+	}
+}
+
+type orderPage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"orderPage"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[order]
+}
+
+type order struct {
+	Customer hmc.Select
+}
+
+func TestBrowserSearchOptions(t *testing.T) {
+	source := hmc.OptionSource{Href: "/customers"}
+	customers := []hmc.Option{
+		{Label: "Acme Ltd", Value: "c-1"},
+		{Label: "Bakery Co", Value: "c-2"},
+		{Label: "Acme Holdings", Value: "c-3"},
+	}
+	newOrder := func() orderPage {
+		return orderPage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[order]{
+				Method: "POST",
+				Elements: order{
+					Customer: hmc.Select{Label: "Customer", Name: "customer", Required: true, Source: &source},
+				},
+			},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(newOrder())
+	})
+	mux.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		p := newOrder()
+		_ = r.ParseForm()
+		p.Form.ExtractFormValue(r.PostForm)
+		p.Message = "ordered for " + p.Form.Elements.Customer.Value()
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(p)
+	})
+	mux.HandleFunc("GET /customers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(source.List(r.URL, customers, 1))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	in := strings.Join([]string{
+		"f 1",
+		"/acme",
+		"1",
+		"q",
+	}, "\n")
+	out := bytes.Buffer{}
+
+	b := newBrowser(strings.NewReader(in), &out)
+	err := b.run(context.Background(), srv.URL+"/orders")
+	assert.FatalErr(t, "running browser", err)
+
+	output := out.String()
+	for _, expected := range []string{
+		"  Enter /TEXT to search for more options.",
+		"  1) ( ) Acme Ltd [c-1]",
+		"  (1 of 2 matches shown)",
+		"  Customer (customer, select, searchable, required):",
+		"      (*) c-1",
+	} {
+		assert.True(t, "output contains "+expected, strings.Contains(output, expected))
+	}
+	if t.Failed() {
+		t.Log(output)
+	}
+}
+
+type notePage struct {
+	hmc.Namespace
+	XMLName xml.Name `xml:"notePage"`
+	Message string   `xml:",omitempty"`
+	Form    hmc.Form[note]
+}
+
+type note struct {
+	Body hmc.Textarea
+}
+
+func TestBrowserTextarea(t *testing.T) {
+	newNote := func() notePage {
+		return notePage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[note]{
+				Method:   "POST",
+				Elements: note{Body: hmc.Textarea{Label: "Body", Name: "body", Required: true}},
+			},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /notes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(newNote())
+	})
+	mux.HandleFunc("POST /notes", func(w http.ResponseWriter, r *http.Request) {
+		p := newNote()
+		_ = r.ParseForm()
+		p.Form.ExtractFormValue(r.PostForm)
+		p.Message = strconv.Quote(p.Form.Elements.Body.Value)
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(p)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	in := strings.Join([]string{
+		"f 1",
+		".", // a value is required
+		"Dear diary,",
+		"  today I wrote a test.",
+		".",
+		"q",
+	}, "\n")
+	out := bytes.Buffer{}
+
+	b := newBrowser(strings.NewReader(in), &out)
+	err := b.run(context.Background(), srv.URL+"/notes")
+	assert.FatalErr(t, "running browser", err)
+
+	output := out.String()
+	for _, expected := range []string{
+		"  Body (body, multi-line, required): \"\"",
+		"    ! a value is required",
+		`  Body (body, multi-line, required): "Dear diary,\n  today I wrote a test."`,
+	} {
+		assert.True(t, "output contains "+expected, strings.Contains(output, expected))
+	}
+	if t.Failed() {
+		t.Log(output)
+	}
+}
+
+func TestBrowserFile(t *testing.T) {
+	type upload struct {
+		File hmc.File
+	}
+	type uploadPage struct {
+		hmc.Namespace
+		XMLName xml.Name `xml:"uploadPage"`
+		Form    hmc.Form[upload]
+	}
+	newUpload := func() uploadPage {
+		return uploadPage{
+			Namespace: hmc.SetNamespace(),
+			Form: hmc.Form[upload]{
+				Method:   "POST",
+				Enctype:  hmc.EnctypeMultipart,
+				Elements: upload{File: hmc.File{Label: "Notes", Name: "notes", Required: true, Accept: []string{".txt"}}},
+			},
+		}
+	}
+
+	var uploaded string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /uploads", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(newUpload())
+	})
+	mux.HandleFunc("POST /uploads", func(w http.ResponseWriter, r *http.Request) {
+		p := newUpload()
+		_ = r.ParseMultipartForm(1 << 20)
+		p.Form.ExtractMultipartForm(r.MultipartForm)
+		if ok, _ := p.Form.Validate(); ok {
+			uploaded = p.Form.Elements.File.Files[0].Filename
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(p)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	err := os.WriteFile(path, []byte("hello"), 0o600)
+	assert.FatalErr(t, "writing file", err)
+
+	in := strings.Join([]string{
+		"f 1",
+		"", // a file is required
+		filepath.Join(t.TempDir(), "missing.txt"),
+		path,
+		"q",
+	}, "\n")
+	out := bytes.Buffer{}
+
+	b := newBrowser(strings.NewReader(in), &out)
+	err = b.run(context.Background(), srv.URL+"/uploads")
+	assert.FatalErr(t, "running browser", err)
+
+	output := out.String()
+	for _, expected := range []string{
+		"  Notes (notes, file, .txt, required)",
+		"    ! a file is required",
+		"missing.txt: no such file or directory",
+	} {
+		assert.True(t, "output contains "+expected, strings.Contains(output, expected))
+	}
+	assert.Eq(t, "file is uploaded", "notes.txt", uploaded)
+	if t.Failed() {
+		t.Log(output)
+	}
+}
+
+func TestBrowserLinkTemplate(t *testing.T) {
+	type orders struct {
+		hmc.Namespace
+		XMLName xml.Name `xml:"orders"`
+		Order   hmc.LinkTemplate
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(orders{
+			Namespace: hmc.SetNamespace(),
+			Order: hmc.LinkTemplate{
+				Label: "Order",
+				Href:  "/orders/{id}{?lang}",
+				Variables: []hmc.TemplateVariable{
+					{Label: "Order ID", Name: "id", Required: true},
+					{Label: "Language", Name: "lang"},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(struct {
+			XMLName xml.Name `xml:"order"`
+		}{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	in := strings.Join([]string{
+		"t 1",
+		"",    // the ID is required
+		"A 1", // needs escaping
+		"",    // no language
+		"q",
+	}, "\n")
+	out := bytes.Buffer{}
+
+	b := newBrowser(strings.NewReader(in), &out)
+	err := b.run(context.Background(), srv.URL+"/orders")
+	assert.FatalErr(t, "running browser", err)
+
+	output := out.String()
+	for _, expected := range []string{
+		"  [t1] Order -> /orders/{id}{?lang}",
+		"Order ID (id, required): ",
+		"    ! a value is required",
+		"Language (lang): ",
+		srv.URL + "/orders/A%201 200 OK",
+	} {
+		assert.True(t, "output contains "+expected, strings.Contains(output, expected))
+	}
+	if t.Failed() {
+		t.Log(output)
+	}
+}
@@ -0,0 +1,38 @@
+// Command hmc is an interactive terminal browser for APIs described with
+// hmc's hypermedia controls.
+//
+// It fetches a resource, lists its links and forms, and prompts for what to
+// do next:
+//
+//	hmc https://example.com/login
+//
+// Enter the number of a link to follow it, or f followed by the number of a
+// form to fill it in field-by-field and submit it. Enter ? for the other
+// commands.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s URL\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	b := newBrowser(os.Stdin, os.Stdout)
+	if err := b.run(context.Background(), flag.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "hmc: %s\n", err)
+		os.Exit(1)
+	}
+}
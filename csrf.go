@@ -0,0 +1,216 @@
+package hmc
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+var (
+	// ErrCSRFTokenMissing is reported by [CSRFToken.Err] if a submission
+	// didn't include the token.
+	ErrCSRFTokenMissing = errors.New("the form's CSRF token is missing")
+	// ErrCSRFTokenInvalid is reported by [CSRFToken.Err] if a submission
+	// included a token that isn't the one issued for the session.
+	ErrCSRFTokenInvalid = errors.New("the form's CSRF token is invalid")
+	// ErrCSRFSecretTooShort is returned by [CSRF.Token] and [CSRF.Issue] if
+	// the Secret is shorter than [MinCSRFSecretLen].
+	ErrCSRFSecretTooShort = fmt.Errorf("the CSRF secret must be at least %d bytes", MinCSRFSecretLen)
+)
+
+// MinCSRFSecretLen is the least number of bytes that [CSRF.Secret] may
+// have.
+const MinCSRFSecretLen = 32
+
+// DefaultCSRFName is the name of a [CSRFToken] issued by a [CSRF] without
+// a Name.
+const DefaultCSRFName = "csrf_token"
+
+// CSRF issues the tokens of [CSRFToken] controls, which protect forms
+// against cross-site request forgery.
+//
+// The token for a session is an HMAC of the session's ID, keyed with
+// Secret, so tokens needn't be stored: a form can be checked by issuing
+// the token again when it is submitted. Secret must be kept private, and
+// must be at least [MinCSRFSecretLen] random bytes.
+//
+//	csrf := hmc.CSRF{Secret: secret}
+//
+//	// When serving the form, and again when it is submitted
+//	if err := csrf.Issue(sessionID, &page.Form); err != nil {
+//	    http.Error(w, err.Error(), http.StatusInternalServerError)
+//	    return
+//	}
+//
+//	// When it is submitted
+//	page.Form.ExtractFormValue(r.PostForm)
+//	if err := page.Form.Elements.CSRF.Err(); err != nil {
+//	    http.Error(w, err.Error(), http.StatusForbidden)
+//	    return
+//	}
+type CSRF struct {
+	Secret []byte
+	// Name is the name that tokens are submitted under. If empty,
+	// [DefaultCSRFName] is used.
+	Name string
+}
+
+// Token returns the token for the session with the given ID. It returns
+// [ErrCSRFSecretTooShort] if Secret is empty or too short to be secure.
+func (c CSRF) Token(session string) (string, error) {
+	if len(c.Secret) < MinCSRFSecretLen {
+		return "", ErrCSRFSecretTooShort
+	}
+	mac := hmac.New(sha256.New, c.Secret)
+	mac.Write([]byte(session))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Issue sets the Name and Value of every [CSRFToken] in v to those for the
+// session with the given ID. v should be a pointer to a [Form], or to
+// anything else containing CSRFTokens.
+//
+// A CSRFToken that already has a Name keeps it. If the token can't be
+// made, as by [CSRF.Token], v is left as it is and the error is returned.
+func (c CSRF) Issue(session string, v any) error {
+	token, err := c.Token(session)
+	if err != nil {
+		return err
+	}
+	walk(reflect.ValueOf(v), func(v reflect.Value) bool {
+		t, ok := asAddressable(v).(*CSRFToken)
+		if ok {
+			t.Name = cmp.Or(t.Name, c.Name, DefaultCSRFName)
+			t.Value = token
+		}
+		return ok
+	})
+	return nil
+}
+
+// CSRFToken is a hidden control holding a token issued by [CSRF], which
+// the client must submit with the form to show that the submission came
+// from the form.
+//
+// It is marshalled as a [Hidden], so clients needn't treat it specially.
+//
+// The token must be issued with [CSRF.Issue] both when the form is served
+// and before it is extracted, so that [CSRFToken.ExtractFormValue] can
+// check the submitted token against it.
+type CSRFToken struct {
+	Name   string   `json:"name"`
+	Value  string   `json:"value"`
+	Errors []string `json:"errors,omitempty"`
+
+	// err is the problem found by ExtractFormValue, if any.
+	err error
+}
+
+func (CSRFToken) controlName() string { return "Hidden" }
+
+func (t *CSRFToken) scope(from, to string) { t.Name = rescopeName(t.Name, from, to) }
+
+func (t CSRFToken) addFormValues(values url.Values) { values.Add(t.Name, t.Value) }
+
+// UnmarshalJSON replaces t with the CSRFToken in data. The outcome of
+// checking a submitted token isn't marshalled, so [CSRFToken.Err] reports
+// nil afterwards.
+func (t *CSRFToken) UnmarshalJSON(data []byte) error {
+	var j csrfTokenJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*t = CSRFToken(j)
+	return nil
+}
+
+// ExtractFormValue checks the value of Name in form against the issued
+// token in Value, and deletes it from form. Value is left as the issued
+// token, so that the form can be served again.
+//
+// If the submitted token is missing or doesn't match, the problem is
+// reported by [CSRFToken.Err] and set as [CSRFToken.Errors], so that
+// [Form.Validate] fails. Errors from an earlier submission are cleared. A CSRFToken whose token wasn't issued never
+// matches. A CSRFToken without a Name reads [DefaultCSRFName].
+func (t *CSRFToken) ExtractFormValue(form url.Values) {
+	name := cmp.Or(t.Name, DefaultCSRFName)
+	submitted := form.Get(name)
+	delete(form, name)
+
+	t.err = nil
+	t.Errors = nil
+	switch {
+	case submitted == "":
+		t.err = ErrCSRFTokenMissing
+	case t.Value == "" || !hmac.Equal([]byte(submitted), []byte(t.Value)):
+		t.err = ErrCSRFTokenInvalid
+	}
+	if t.err != nil {
+		t.Errors = append(t.Errors, t.err.Error())
+	}
+}
+
+// Err returns [ErrCSRFTokenMissing] or [ErrCSRFTokenInvalid] if
+// [CSRFToken.ExtractFormValue] found a problem with the submitted token,
+// or nil otherwise.
+func (t CSRFToken) Err() error {
+	return t.err
+}
+
+// FirstError returns the first of [CSRFToken.Errors], or "" if there are
+// none.
+func (t CSRFToken) FirstError() string {
+	return firstError(t.Errors)
+}
+
+// FieldErrors reports each of [CSRFToken.Errors].
+func (t CSRFToken) FieldErrors() []FieldError {
+	return fieldErrors(t.Name, t.Errors)
+}
+
+// MarshalXML marshals the token as a c:Hidden element, with any Errors as
+// its children.
+func (t CSRFToken) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Hidden"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: t.Name})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: t.Value})
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := encodeErrors(e, t.Errors); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:Hidden element as marshalled by
+// [CSRFToken.MarshalXML].
+func (t *CSRFToken) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*t = CSRFToken{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "name":
+			t.Name = a.Value
+		case "value":
+			t.Value = a.Value
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Error") {
+			return decodeError(d, child, &t.Errors)
+		}
+		return d.Skip()
+	})
+}
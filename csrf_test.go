@@ -0,0 +1,129 @@
+package hmc_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type comment struct {
+	CSRF hmc.CSRFToken
+	Post hmc.Hidden
+	Body hmc.Textarea
+}
+
+var csrf = hmc.CSRF{Secret: []byte("0123456789abcdef0123456789abcdef")}
+
+func newComment() hmc.Form[comment] {
+	return hmc.Form[comment]{
+		Method: "POST",
+		Elements: comment{
+			Post: hmc.Hidden{Name: "post", Value: "42"},
+			Body: hmc.Textarea{Label: "Comment", Name: "body"},
+		},
+	}
+}
+
+// issuedToken returns the token of csrf for session.
+func issuedToken(t *testing.T, session string) string {
+	t.Helper()
+	token, err := csrf.Token(session)
+	assert.FatalErr(t, "making token", err)
+	return token
+}
+
+func TestCSRFIssue(t *testing.T) {
+	f := newComment()
+	err := csrf.Issue("session-1", &f)
+	assert.FatalErr(t, "issuing", err)
+
+	token := f.Elements.CSRF
+	other, err := hmc.CSRF{Secret: []byte("fedcba9876543210fedcba9876543210")}.Token("session-1")
+	assert.FatalErr(t, "making token with another secret", err)
+	assert.Eq(t, "default name", hmc.DefaultCSRFName, token.Name)
+	assert.Eq(t, "token", issuedToken(t, "session-1"), token.Value)
+	assert.True(t, "tokens differ between sessions", issuedToken(t, "session-1") != issuedToken(t, "session-2"))
+	assert.True(t, "tokens differ between secrets", issuedToken(t, "session-1") != other)
+}
+
+func TestCSRFShortSecret(t *testing.T) {
+	for _, secret := range [][]byte{nil, []byte("short")} {
+		c := hmc.CSRF{Secret: secret}
+		_, err := c.Token("session-1")
+		assert.FatalErrIs(t, "token", err, hmc.ErrCSRFSecretTooShort)
+
+		f := newComment()
+		err = c.Issue("session-1", &f)
+		assert.FatalErrIs(t, "issuing", err, hmc.ErrCSRFSecretTooShort)
+		assert.Eq(t, "nothing is issued", "", f.Elements.CSRF.Value)
+	}
+}
+
+func TestCSRFTokenExtractFormValue(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		form     url.Values
+		expected error
+	}{
+		{"valid", url.Values{"csrf_token": {issuedToken(t, "session-1")}}, nil},
+		{"missing", url.Values{}, hmc.ErrCSRFTokenMissing},
+		{"empty", url.Values{"csrf_token": {""}}, hmc.ErrCSRFTokenMissing},
+		{"other session", url.Values{"csrf_token": {issuedToken(t, "session-2")}}, hmc.ErrCSRFTokenInvalid},
+		{"garbage", url.Values{"csrf_token": {"garbage"}}, hmc.ErrCSRFTokenInvalid},
+	} {
+		f := newComment()
+		err := csrf.Issue("session-1", &f)
+		assert.FatalErr(t, c.name+": issuing", err)
+
+		f.ExtractFormValue(c.form)
+
+		token := f.Elements.CSRF
+		assert.True(t, c.name, errors.Is(token.Err(), c.expected))
+		assert.Eq(t, c.name+": issued token is kept", issuedToken(t, "session-1"), token.Value)
+		ok, failures := f.Validate()
+		assert.Eq(t, c.name+": valid", c.expected == nil, ok)
+		if c.expected != nil {
+			assert.SlicesEq(t, c.name+": failures", []hmc.FieldError{{Name: "csrf_token", Message: c.expected.Error()}}, failures)
+		}
+	}
+}
+
+func TestCSRFTokenExtractFormValueAgain(t *testing.T) {
+	f := newComment()
+	err := csrf.Issue("session-1", &f)
+	assert.FatalErr(t, "issuing", err)
+
+	f.ExtractFormValue(url.Values{"csrf_token": {"stale"}})
+	f.ExtractFormValue(url.Values{"csrf_token": {"stale"}})
+	assert.SlicesEq(t, "errors aren't repeated", []string{hmc.ErrCSRFTokenInvalid.Error()}, f.Elements.CSRF.Errors)
+
+	f.ExtractFormValue(url.Values{"csrf_token": {issuedToken(t, "session-1")}})
+	assert.Eq(t, "errors are cleared", 0, len(f.Elements.CSRF.Errors))
+}
+
+func TestCSRFTokenNotIssued(t *testing.T) {
+	f := newComment()
+	f.Elements.CSRF.Name = "csrf_token"
+	f.ExtractFormValue(url.Values{"csrf_token": {"anything"}})
+	assert.True(t, "unissued token never matches", errors.Is(f.Elements.CSRF.Err(), hmc.ErrCSRFTokenInvalid))
+
+	var token hmc.CSRFToken
+	form := url.Values{"": {"anything"}, "csrf_token": {"garbage"}}
+	token.ExtractFormValue(form)
+	assert.True(t, "unnamed token reads the default name", errors.Is(token.Err(), hmc.ErrCSRFTokenInvalid))
+	assert.SlicesEq(t, "empty name is left", []string{"anything"}, form[""])
+	assert.Eq(t, "default name is deleted", 1, len(form))
+}
+
+func TestHiddenExtractFormValue(t *testing.T) {
+	h := hmc.Hidden{Name: "post", Value: "42"}
+	form := url.Values{"post": {"43"}}
+
+	h.ExtractFormValue(form)
+
+	assert.Eq(t, "value", "43", h.Value)
+	assert.Eq(t, "value is consumed", 0, len(form))
+}
@@ -0,0 +1,193 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+)
+
+// Fieldset is analogous to HTML's <fieldset>. It groups the controls in
+// Elements under a Legend.
+//
+// If Name is set, the names of the controls in Elements are scoped to it
+// in bracket notation, so that the same struct of controls can be used
+// more than once in a form. In a Fieldset named "billing", a control named
+// "street" is marshalled as "billing[street]", and takes its value from
+// "billing[street]" in [Fieldset.ExtractFormValue]. The controls in
+// Elements keep their own names; see [Fieldset.Scoped].
+type Fieldset[T any] struct {
+	Legend   string `json:"legend,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Elements T      `json:"elements"`
+}
+
+func (Fieldset[T]) controlName() string { return "Fieldset" }
+
+// scope only needs to move the Fieldset itself, since the names of its
+// Elements are relative to it.
+func (f *Fieldset[T]) scope(from, to string) { f.Name = rescopeName(f.Name, from, to) }
+
+func (f Fieldset[T]) addFormValues(values url.Values) {
+	scoped := f.Scoped()
+	for name, vs := range formValues(reflect.ValueOf(&scoped).Elem()) {
+		values[name] = append(values[name], vs...)
+	}
+}
+
+// Scoped returns a copy of Elements with the names of its controls scoped
+// to Name, as they are marshalled.
+func (f Fieldset[T]) Scoped() T {
+	elements := deepCopy(reflect.ValueOf(&f.Elements).Elem())
+	scopeNames(elements, "", f.Name)
+	return elements.Interface().(T)
+}
+
+// MarshalJSON marshals the Fieldset with the names of its controls scoped
+// to Name.
+func (f Fieldset[T]) MarshalJSON() ([]byte, error) {
+	j := fieldsetJson[T](f)
+	j.Elements = f.Scoped()
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a Fieldset as marshalled by
+// [Fieldset.MarshalJSON], replacing f and restoring the names of its
+// controls to be relative to the Fieldset.
+func (f *Fieldset[T]) UnmarshalJSON(data []byte) error {
+	var j fieldsetJson[T]
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*f = Fieldset[T](j)
+	f.unscope()
+	return nil
+}
+
+// unscope restores the names of the controls in Elements after decoding.
+func (f *Fieldset[T]) unscope() {
+	if f.Name != "" {
+		scopeNames(reflect.ValueOf(&f.Elements).Elem(), f.Name, "")
+	}
+}
+
+// ExtractFormValue calls ExtractFormValue on every [FormValueExtractor] in
+// Elements, as [Form.ExtractFormValue] does, with the values in form that
+// are scoped to Name.
+func (f *Fieldset[T]) ExtractFormValue(form url.Values) {
+	elements := reflect.ValueOf(&f.Elements).Elem()
+	if f.Name == "" {
+		extractFormValues(elements, form)
+		return
+	}
+
+	scoped := Map{Name: f.Name}
+	relative := url.Values{}
+	for name, values := range form {
+		if path, ok := scoped.Path(name); ok {
+			relative[Map{}.NamedKey(path...)] = values
+		}
+	}
+
+	extractFormValues(elements, relative)
+
+	// Leave what the controls didn't consume for those that come after
+	for name := range form {
+		path, ok := scoped.Path(name)
+		if !ok {
+			continue
+		}
+		if values, left := relative[Map{}.NamedKey(path...)]; left {
+			form[name] = values
+		} else {
+			delete(form, name)
+		}
+	}
+}
+
+// ExtractFormFiles calls ExtractFormFiles on every [FileExtractor] in
+// Elements, as [Form.ExtractMultipartForm] does, with the files in files
+// that are scoped to Name.
+func (f *Fieldset[T]) ExtractFormFiles(files map[string][]*multipart.FileHeader) {
+	elements := reflect.ValueOf(&f.Elements).Elem()
+	if f.Name == "" {
+		extractFormFiles(elements, files)
+		return
+	}
+
+	scoped := Map{Name: f.Name}
+	relative := map[string][]*multipart.FileHeader{}
+	for name, fhs := range files {
+		if path, ok := scoped.Path(name); ok {
+			relative[Map{}.NamedKey(path...)] = fhs
+		}
+	}
+
+	extractFormFiles(elements, relative)
+
+	for name := range files {
+		path, ok := scoped.Path(name)
+		if !ok {
+			continue
+		}
+		if _, left := relative[Map{}.NamedKey(path...)]; !left {
+			delete(files, name)
+		}
+	}
+}
+
+// Validate calls Validate on every [Validator] in Elements. The controls
+// are scoped to Name while they are validated, so that their messages give
+// the names they are submitted under.
+func (f *Fieldset[T]) Validate() {
+	elements := reflect.ValueOf(&f.Elements).Elem()
+	scopeNames(elements, "", f.Name)
+	validateControls(elements)
+	scopeNames(elements, f.Name, "")
+}
+
+// FieldErrors reports the errors of every [ErrorReporter] in Elements,
+// under their names scoped to Name.
+func (f Fieldset[T]) FieldErrors() []FieldError {
+	errs := controlErrors(reflect.ValueOf(&f.Elements).Elem())
+	for i := range errs {
+		errs[i].Name = rescopeName(errs[i].Name, "", f.Name)
+	}
+	return errs
+}
+
+// MarshalXML marshals the Fieldset as a c:Fieldset element, with the
+// controls of Elements as its children, scoped to Name.
+func (f Fieldset[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Fieldset"}}
+	if f.Legend != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "legend"}, Value: f.Legend})
+	}
+	if f.Name != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: f.Name})
+	}
+	return e.EncodeElement(f.Scoped(), start)
+}
+
+// UnmarshalXML decodes a c:Fieldset element as marshalled by
+// [Fieldset.MarshalXML], unscoping the names of its controls. The controls
+// are decoded as those of [Form.Elements] are.
+func (f *Fieldset[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = Fieldset[T]{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "legend":
+			f.Legend = a.Value
+		case "name":
+			f.Name = a.Value
+		}
+	}
+
+	if err := decodeValue(d, reflect.ValueOf(&f.Elements).Elem(), start); err != nil {
+		return err
+	}
+	f.unscope()
+	return nil
+}
@@ -0,0 +1,82 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type checkout struct {
+	Billing  hmc.Fieldset[address]
+	Shipping hmc.Fieldset[address]
+	Notes    hmc.Map
+}
+
+func newAddress() address {
+	return address{
+		Street: hmc.Input{Label: "Street", Name: "street", Required: true},
+		City:   &hmc.Input{Label: "City", Name: "city"},
+	}
+}
+
+func newCheckout() hmc.Form[checkout] {
+	return hmc.Form[checkout]{
+		Method: "POST",
+		Elements: checkout{
+			Billing:  hmc.Fieldset[address]{Legend: "Billing address", Name: "billing", Elements: newAddress()},
+			Shipping: hmc.Fieldset[address]{Legend: "Shipping address", Name: "shipping", Elements: newAddress()},
+		},
+	}
+}
+
+func TestFieldsetExtractFormValue(t *testing.T) {
+	f := newCheckout()
+	form := url.Values{
+		"billing[street]":  {"1 Main St"},
+		"billing[city]":    {"Wellington"},
+		"billing[extra]":   {"?"},
+		"shipping[street]": {"2 High St"},
+		"street":           {"unscoped"},
+	}
+
+	f.ExtractFormValue(form)
+
+	e := f.Elements
+	assert.Eq(t, "billing street", "1 Main St", e.Billing.Elements.Street.Value)
+	assert.Eq(t, "billing city", "Wellington", e.Billing.Elements.City.Value)
+	assert.Eq(t, "shipping street", "2 High St", e.Shipping.Elements.Street.Value)
+	assert.Eq(t, "names are left unscoped", "street", e.Shipping.Elements.Street.Name)
+	assert.SlicesEq(t, "leftovers go to the map", []string{"?"}, e.Notes.Get("billing", "extra"))
+	assert.SlicesEq(t, "unscoped entries go to the map", []string{"unscoped"}, e.Notes.Entries["street"])
+}
+
+func TestFieldsetValidate(t *testing.T) {
+	f := newCheckout()
+	f.ExtractFormValue(url.Values{"billing[street]": {"1 Main St"}})
+
+	ok, failures := f.Validate()
+
+	assert.Eq(t, "invalid", false, ok)
+	assert.SlicesEq(t, "failures are scoped", []hmc.FieldError{
+		{Name: "shipping[street]", Message: `"shipping[street]" is required`},
+	}, failures)
+	assert.Eq(t, "names are left unscoped", "street", f.Elements.Shipping.Elements.Street.Name)
+}
+
+func TestFieldsetInList(t *testing.T) {
+	l := hmc.List[hmc.Fieldset[address]]{
+		Name:      "addresses",
+		Prototype: hmc.Fieldset[address]{Name: "home", Elements: newAddress()},
+	}
+	l.ExtractFormValue(url.Values{
+		"addresses[0][home][street]": {"1 Main St"},
+		"addresses[1][home][street]": {""},
+	})
+
+	assert.Eq(t, "blank item is left out", 1, len(l.Items))
+	assert.Eq(t, "fieldset is scoped to the item", "addresses[0][home]", l.Items[0].Name)
+	assert.Eq(t, "value is extracted", "1 Main St", l.Items[0].Elements.Street.Value)
+	assert.Eq(t, "controls are scoped when marshalled", "addresses[0][home][street]", l.Items[0].Scoped().Street.Name)
+}
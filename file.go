@@ -0,0 +1,251 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FileExtractor is implemented by controls that take uploaded files from a
+// multipart form submission, such as [File].
+//
+// Implementations should delete the files they consume from files so that
+// later controls only see what is left over.
+type FileExtractor interface {
+	ExtractFormFiles(files map[string][]*multipart.FileHeader)
+}
+
+// File is analogous to HTML's <input type="file">. It describes files that
+// the client should upload, and holds the files that were uploaded.
+//
+// A form with a File should have an Enctype of [EnctypeMultipart], and be
+// read with [Form.ExtractMultipartForm].
+//
+// Accept lists the kinds of file that are accepted, as with HTML's accept
+// attribute: each is either a file extension, such as ".pdf", a MIME type,
+// such as "application/pdf", or a MIME type with a wildcard subtype, such
+// as "image/*". If Accept is empty, any kind of file is accepted.
+//
+// MaxSize limits the size of each file, in bytes, and MaxFiles limits the
+// number of files if Multiple is set. Neither is limited if zero.
+type File struct {
+	Label    string   `json:"label"`
+	Name     string   `json:"name"`
+	Required bool     `json:"required,omitempty"`
+	Multiple bool     `json:"multiple,omitempty"`
+	Accept   []string `json:"accept,omitempty"`
+	MaxSize  int64    `json:"maxsize,omitempty"`
+	MaxFiles uint     `json:"maxfiles,omitempty"`
+	// Files are the uploaded files, set by [File.ExtractFormFiles]. They
+	// aren't marshalled.
+	Files  []*multipart.FileHeader `json:"-"`
+	Errors []string                `json:"errors,omitempty"`
+}
+
+// File is marshalled as a c:Input, so that it is decoded positionally
+// alongside them.
+func (File) controlName() string { return "Input" }
+
+func (f *File) scope(from, to string) { f.Name = rescopeName(f.Name, from, to) }
+
+// MarshalJSON marshals the File with a "type" of "file", as with
+// [File.MarshalXML].
+func (f File) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		fileJson
+	}{"file", fileJson(f)})
+}
+
+// UnmarshalJSON decodes a File as marshalled by [File.MarshalJSON],
+// ignoring its "type". Files are never marshalled, so any that were
+// uploaded to f are dropped.
+func (f *File) UnmarshalJSON(data []byte) error {
+	var j fileJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*f = File(j)
+	return nil
+}
+
+// ExtractFormFiles sets Files to the files uploaded under Name, and
+// deletes them from files.
+func (f *File) ExtractFormFiles(files map[string][]*multipart.FileHeader) {
+	uploaded, ok := files[f.Name]
+	if !ok {
+		return
+	}
+	f.Files = uploaded
+	delete(files, f.Name)
+}
+
+// Validate checks the uploaded Files, appending a message for each problem
+// to [File.Errors]:
+//
+//   - If [File.Required] is set, a file must be uploaded.
+//   - Only one file may be uploaded unless [File.Multiple] is set, and then
+//     no more than [File.MaxFiles].
+//   - Each file must be no larger than [File.MaxSize].
+//   - Each file must be one of the kinds in [File.Accept]. Its MIME type is
+//     the Content-Type given by the client, which the server should not
+//     rely on to be truthful.
+//
+// The messages of an earlier call to Validate are replaced, and other
+// errors that are already present are kept.
+func (f *File) Validate() {
+	f.Errors = fileMessages.clear(f.Errors)
+	n := len(f.Files)
+	if f.Required && n == 0 {
+		f.Errors = append(f.Errors, fmt.Sprintf(msgRequired, f.Name))
+	}
+	if !f.Multiple && n > 1 {
+		f.Errors = append(f.Errors, fmt.Sprintf(msgNotMultiple, f.Name, n))
+	}
+	if f.Multiple && f.MaxFiles > 0 && int(f.MaxFiles) < n {
+		f.Errors = append(f.Errors, fmt.Sprintf(msgTooManyFiles, f.Name, f.MaxFiles, n))
+	}
+
+	for _, fh := range f.Files {
+		if f.MaxSize > 0 && fh.Size > f.MaxSize {
+			f.Errors = append(f.Errors, fmt.Sprintf(msgTooLarge, f.Name, f.MaxSize, fh.Filename, fh.Size))
+		}
+		if !f.accepts(fh) {
+			f.Errors = append(f.Errors, fmt.Sprintf(msgNotAccepted, fh.Filename, f.Name))
+		}
+	}
+}
+
+const (
+	msgNotMultiple  = "%#v supports at most 1 file (currently %d files)"
+	msgTooManyFiles = "%#v supports at most %d files (currently %d files)"
+	msgTooLarge     = "%#v supports files of at most %d bytes (%#v is %d bytes)"
+	msgNotAccepted  = "%#v is not an accepted kind of file for %#v"
+)
+
+var fileMessages = newMessageFormats(msgRequired, msgNotMultiple, msgTooManyFiles, msgTooLarge, msgNotAccepted)
+
+// accepts reports whether the file fh is one of the kinds in Accept.
+func (f File) accepts(fh *multipart.FileHeader) bool {
+	if len(f.Accept) == 0 {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(fh.Header.Get("Content-Type"))
+	ext := strings.ToLower(path.Ext(fh.Filename))
+	for _, a := range f.Accept {
+		a = strings.ToLower(strings.TrimSpace(a))
+		switch {
+		case strings.HasPrefix(a, "."):
+			if ext == a {
+				return true
+			}
+		case strings.HasSuffix(a, "/*"):
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(a, "*")) {
+				return true
+			}
+		case mediaType == a:
+			return true
+		}
+	}
+	return false
+}
+
+// FirstError returns the first of [File.Errors], or "" if there are none.
+func (f File) FirstError() string {
+	return firstError(f.Errors)
+}
+
+// FieldErrors reports each of [File.Errors].
+func (f File) FieldErrors() []FieldError {
+	return fieldErrors(f.Name, f.Errors)
+}
+
+// MarshalXML marshals the File as a c:Input element with a type of
+// "file". Accept is given as a comma-separated list, as in HTML.
+func (f File) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Input"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: f.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: f.Name})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: "file"})
+	if len(f.Accept) > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "accept"}, Value: strings.Join(f.Accept, ",")})
+	}
+	if f.Multiple {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "multiple"}})
+	}
+	if f.MaxSize > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxsize"}, Value: fmt.Sprintf("%d", f.MaxSize)})
+	}
+	if f.MaxFiles > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxfiles"}, Value: fmt.Sprintf("%d", f.MaxFiles)})
+	}
+	if f.Required {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "required"}, Value: "true"})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := encodeErrors(e, f.Errors); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:Input element as marshalled by
+// [File.MarshalXML].
+func (f *File) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = File{}
+
+	for _, a := range start.Attr {
+		var err error
+		switch a.Name.Local {
+		case "label":
+			f.Label = a.Value
+		case "name":
+			f.Name = a.Value
+		case "accept":
+			for accept := range strings.SplitSeq(a.Value, ",") {
+				f.Accept = append(f.Accept, strings.TrimSpace(accept))
+			}
+		case "multiple":
+			f.Multiple = parseBoolAttr(a.Value)
+		case "maxsize":
+			f.MaxSize, err = strconv.ParseInt(a.Value, 10, 64)
+		case "maxfiles":
+			f.MaxFiles, err = parseUintAttr(a.Value)
+		case "required":
+			f.Required = parseBoolAttr(a.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("c:Input %s attribute: %w", a.Name.Local, err)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Error") {
+			return decodeError(d, child, &f.Errors)
+		}
+		return d.Skip()
+	})
+}
+
+// extractFormFiles calls ExtractFormFiles on every [FileExtractor] in v, in
+// declaration order.
+func extractFormFiles(v reflect.Value, files map[string][]*multipart.FileHeader) {
+	walk(v, func(v reflect.Value) bool {
+		if x, ok := asAddressable(v).(FileExtractor); ok {
+			x.ExtractFormFiles(files)
+			return true
+		}
+		return false
+	})
+}
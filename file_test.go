@@ -0,0 +1,136 @@
+package hmc_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type upload struct {
+	Title       hmc.Input
+	Attachments hmc.File
+	Avatar      hmc.Fieldset[avatar]
+}
+
+type avatar struct {
+	Image hmc.File
+}
+
+func newUpload() hmc.Form[upload] {
+	return hmc.Form[upload]{
+		Method:  "POST",
+		Enctype: hmc.EnctypeMultipart,
+		Elements: upload{
+			Title:       hmc.Input{Label: "Title", Name: "title"},
+			Attachments: hmc.File{Label: "Attachments", Name: "attachments", Multiple: true, MaxFiles: 2, MaxSize: 10, Accept: []string{".pdf", "image/*"}},
+			Avatar: hmc.Fieldset[avatar]{Name: "avatar", Elements: avatar{
+				Image: hmc.File{Label: "Image", Name: "image", Required: true, Accept: []string{"image/png"}},
+			}},
+		},
+	}
+}
+
+type part struct {
+	name, filename, contentType, content string
+}
+
+// multipartForm encodes and parses parts, as a server would receive them.
+func multipartForm(t *testing.T, values map[string]string, parts ...part) *multipart.Form {
+	t.Helper()
+	body := bytes.Buffer{}
+	w := multipart.NewWriter(&body)
+	for name, value := range values {
+		assert.FatalErr(t, "writing field", w.WriteField(name, value))
+	}
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+p.name+`"; filename="`+p.filename+`"`)
+		h.Set("Content-Type", p.contentType)
+		pw, err := w.CreatePart(h)
+		assert.FatalErr(t, "creating part", err)
+		_, err = pw.Write([]byte(p.content))
+		assert.FatalErr(t, "writing part", err)
+	}
+	assert.FatalErr(t, "closing writer", w.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", &body)
+	assert.FatalErr(t, "creating request", err)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	assert.FatalErr(t, "parsing form", req.ParseMultipartForm(1<<20))
+	return req.MultipartForm
+}
+
+func TestFormExtractMultipartForm(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		values      map[string]string
+		parts       []part
+		attachments []string
+		images      int
+		leftover    int
+		failures    []hmc.FieldError
+	}{
+		{
+			"valid", map[string]string{"title": "Report"}, []part{
+				{"attachments", "report.pdf", "application/pdf", "%PDF"},
+				{"attachments", "chart.png", "image/png", "PNG"},
+				{"avatar[image]", "me.png", "image/png", "PNG"},
+				{"other", "other.txt", "text/plain", "?"},
+			},
+			[]string{"report.pdf", "chart.png"}, 1, 1, nil,
+		},
+		{
+			"invalid", nil, []part{
+				{"attachments", "notes.txt", "text/plain", "notes"},
+				{"attachments", "huge.pdf", "application/pdf", "%PDF and more"},
+				{"attachments", "photo.jpg", "image/jpeg", "JPG"},
+			},
+			[]string{"notes.txt", "huge.pdf", "photo.jpg"}, 0, 0, []hmc.FieldError{
+				{Name: "attachments", Message: `"attachments" supports at most 2 files (currently 3 files)`},
+				{Name: "attachments", Message: `"notes.txt" is not an accepted kind of file for "attachments"`},
+				{Name: "attachments", Message: `"attachments" supports files of at most 10 bytes ("huge.pdf" is 13 bytes)`},
+				{Name: "avatar[image]", Message: `"avatar[image]" is required`},
+			},
+		},
+	} {
+		f := newUpload()
+		form := multipartForm(t, c.values, c.parts...)
+
+		f.ExtractMultipartForm(form)
+		ok, failures := f.Validate()
+
+		e := f.Elements
+		assert.Eq(t, c.name+": title", c.values["title"], e.Title.Value)
+		var attachments []string
+		for _, fh := range e.Attachments.Files {
+			attachments = append(attachments, fh.Filename)
+		}
+		assert.SlicesEq(t, c.name+": attachments", c.attachments, attachments)
+		assert.Eq(t, c.name+": scoped file", c.images, len(e.Avatar.Elements.Image.Files))
+		assert.Eq(t, c.name+": leftover files", c.leftover, len(form.File))
+		assert.Eq(t, c.name+": valid", len(c.failures) == 0, ok)
+		assert.SlicesEq(t, c.name+": failures", c.failures, failures)
+	}
+}
+
+func TestFileValidateSingle(t *testing.T) {
+	f := hmc.File{Name: "doc"}
+	f.ExtractFormFiles(multipartForm(t, nil,
+		part{"doc", "a.txt", "text/plain", "a"},
+		part{"doc", "b.txt", "text/plain", "b"},
+	).File)
+
+	f.Validate()
+
+	assert.SlicesEq(t, "errors", []string{`"doc" supports at most 1 file (currently 2 files)`}, f.Errors)
+
+	f.Files = f.Files[:1]
+	f.Validate()
+
+	assert.Eq(t, "errors are replaced when validated again", 0, len(f.Errors))
+}
@@ -1,9 +1,22 @@
 package hmc
 
 import (
+	"encoding/json"
 	"encoding/xml"
+	"mime/multipart"
+	"net/url"
+	"reflect"
 )
 
+// FormValueExtractor is implemented by controls that take their value from
+// submitted form data, such as [Input], [Select] and [Map].
+//
+// Implementations should delete the values they consume from form so that
+// later controls only see what is left over.
+type FormValueExtractor interface {
+	ExtractFormValue(form url.Values)
+}
+
 // Form is analogous to HTML's <form> which represents a state transition that requires input from the client.
 // It describes what data is needed, how it should be submitted, and where
 // it should be sent.
@@ -12,17 +25,58 @@ import (
 // and elements semantically related to the form,
 // which would usually be [Input], [Select], [Map], [Link], etc.
 // but might also be something like `Error string` or `Warning string` fields.
+//
+// Action is where the form should be submitted. It may be relative to the
+// URL the form was served from, and if empty the form should be submitted
+// to that same URL, as in HTML. See [Form.ResolveAction].
+//
+// Enctype is the media type that the submission should be encoded as,
+// usually one of [EnctypeURLEncoded], [EnctypeMultipart] or [EnctypeJSON].
+// If empty, [EnctypeURLEncoded] is implied.
 type Form[T any] struct {
 	Method   string `json:"method,omitempty"`
+	Action   string `json:"action,omitempty"`
+	Enctype  string `json:"enctype,omitempty"`
 	Elements T      `json:"elements"`
 }
 
+// The encoding types that [Form.Enctype] is usually one of.
+const (
+	// EnctypeURLEncoded submits the values as URL-encoded name=value pairs,
+	// like a query string. It is implied when Enctype is empty.
+	EnctypeURLEncoded = "application/x-www-form-urlencoded"
+	// EnctypeMultipart submits each value as a part of a multipart body.
+	// Forms with a [File] must use it, since it is the only one that can
+	// carry files.
+	EnctypeMultipart = "multipart/form-data"
+	// EnctypeJSON submits the values as a JSON object, with the values of
+	// each name as a string, or an array of strings if there are several.
+	EnctypeJSON = "application/json"
+)
+
+// UnmarshalJSON replaces f with the Form in data. Its Elements are decoded
+// afresh, rather than into the controls that f already holds.
+func (f *Form[T]) UnmarshalJSON(data []byte) error {
+	var j formJson[T]
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*f = Form[T](j)
+	return nil
+}
+
 func (i Form[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name = xml.Name{Local: "c:Form"}
 
 	if i.Method != "" {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "method"}, Value: i.Method})
 	}
+	if i.Action != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "action"}, Value: i.Action})
+	}
+	if i.Enctype != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "enctype"}, Value: i.Enctype})
+	}
 
 	err := e.EncodeToken(start)
 	if err != nil {
@@ -36,3 +90,114 @@ func (i Form[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 	return e.EncodeToken(start.End())
 }
+
+func (Form[T]) controlName() string { return "Form" }
+
+// UnmarshalXML decodes a c:Form element as marshalled by [Form.MarshalXML].
+//
+// Every control is marshalled under the same element name, so if Elements
+// is a struct, the controls in the document are assigned to the control
+// fields of Elements in declaration order, which is the order they were
+// marshalled in. Other elements are matched to fields by name, as with
+// [xml.Unmarshal].
+func (f *Form[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = Form[T]{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "method":
+			f.Method = a.Value
+		case "action":
+			f.Action = a.Value
+		case "enctype":
+			f.Enctype = a.Value
+		}
+	}
+
+	decoded := false
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if decoded {
+			return d.Skip()
+		}
+		decoded = true
+		return decodeValue(d, reflect.ValueOf(&f.Elements).Elem(), child)
+	})
+}
+
+// ExtractFormValue walks Elements and calls ExtractFormValue on every
+// [FormValueExtractor] it finds, in declaration order.
+//
+// Nested structs, non-nil pointers and interfaces, slices and arrays are
+// descended into. Because extraction happens in order, a catch-all [Map]
+// declared last will only receive the entries that no other control
+// consumed.
+func (f *Form[T]) ExtractFormValue(form url.Values) {
+	extractFormValues(reflect.ValueOf(&f.Elements).Elem(), form)
+}
+
+// ExtractMultipartForm extracts the values of a multipart form submission
+// as [Form.ExtractFormValue] does, and then calls ExtractFormFiles on
+// every [FileExtractor] in Elements with its files, in declaration order.
+//
+// As with ExtractFormValue, the values and files that are consumed are
+// deleted from form.
+func (f *Form[T]) ExtractMultipartForm(form *multipart.Form) {
+	elements := reflect.ValueOf(&f.Elements).Elem()
+	extractFormValues(elements, url.Values(form.Value))
+	extractFormFiles(elements, form.File)
+}
+
+// formValuer is implemented by controls that can say what values they
+// would be submitted with, as a browser would submit them.
+type formValuer interface {
+	addFormValues(values url.Values)
+}
+
+// formValues returns the values that the controls in v would be submitted
+// with if left as they are.
+func formValues(v reflect.Value) url.Values {
+	values := url.Values{}
+	walk(v, func(v reflect.Value) bool {
+		if c, ok := asAddressable(v).(formValuer); ok {
+			c.addFormValues(values)
+			return true
+		}
+		return false
+	})
+	return values
+}
+
+// extractFormValues calls ExtractFormValue on every [FormValueExtractor]
+// in v, in declaration order.
+func extractFormValues(v reflect.Value, form url.Values) {
+	walk(v, func(v reflect.Value) bool {
+		if x, ok := asAddressable(v).(FormValueExtractor); ok {
+			x.ExtractFormValue(form)
+			return true
+		}
+		return false
+	})
+}
+
+// Validate walks Elements in declaration order, calling Validate on every
+// [Validator] and then collecting the errors of every [ErrorReporter].
+//
+// Errors are left on each control so that the form can be re-rendered for
+// the client. Errors set by hand before calling Validate are included in
+// failures.
+func (f *Form[T]) Validate() (ok bool, failures []FieldError) {
+	elements := reflect.ValueOf(&f.Elements).Elem()
+	validateControls(elements)
+	failures = controlErrors(elements)
+	return len(failures) == 0, failures
+}
+
+// ResolveAction resolves [Form.Action] against base, which should be the
+// URL of the request that the form was served in response to.
+func (f Form[T]) ResolveAction(base *url.URL) (*url.URL, error) {
+	action, err := url.Parse(f.Action)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(action), nil
+}
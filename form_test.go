@@ -0,0 +1,105 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type address struct {
+	Street hmc.Input
+	City   *hmc.Input
+}
+
+type order struct {
+	Name      hmc.Input
+	Address   address
+	Extras    []hmc.Select
+	Missing   *hmc.Input
+	Leftovers hmc.Map
+	unwired   hmc.Input
+}
+
+func TestFormExtractFormValueWalksElements(t *testing.T) {
+	f := hmc.Form[order]{
+		Elements: order{
+			Name: hmc.Input{Name: "name"},
+			Address: address{
+				Street: hmc.Input{Name: "street"},
+				City:   &hmc.Input{Name: "city"},
+			},
+			Extras: []hmc.Select{
+				{Name: "sauce", Options: []hmc.Option{{Value: "bbq"}, {Value: "aioli"}}},
+				{Name: "side", Options: []hmc.Option{{Value: "chips"}}},
+			},
+			Leftovers: hmc.Map{},
+			unwired:   hmc.Input{Name: "unwired"},
+		},
+	}
+	form := url.Values{
+		"name":    {"Jo"},
+		"street":  {"1 Main St"},
+		"city":    {"Wellington"},
+		"sauce":   {"aioli"},
+		"side":    {"chips"},
+		"unwired": {"x"},
+		"note":    {"no onions"},
+	}
+
+	f.ExtractFormValue(form)
+
+	e := f.Elements
+	assert.Eq(t, "top-level input", "Jo", e.Name.Value)
+	assert.Eq(t, "nested input", "1 Main St", e.Address.Street.Value)
+	assert.Eq(t, "input behind pointer", "Wellington", e.Address.City.Value)
+	assert.Eq(t, "first select in slice", "aioli", e.Extras[0].Value())
+	assert.Eq(t, "second select in slice", "chips", e.Extras[1].Value())
+	assert.Eq(t, "unexported fields are left alone", "", e.unwired.Value)
+	assert.Eq(t, "map collects leftovers", 2, len(e.Leftovers.Entries))
+	assert.SlicesEq(t, "map collects unmatched entry", []string{"no onions"}, e.Leftovers.Entries["note"])
+	assert.Eq(t, "everything extracted", 0, len(form))
+}
+
+func TestFormValidate(t *testing.T) {
+	f := hmc.Form[order]{
+		Elements: order{
+			Name: hmc.Input{Name: "name", Required: true},
+			Address: address{
+				Street: hmc.Input{Name: "street", Value: "1", MinLength: 3},
+				City:   &hmc.Input{Name: "city", Value: "Wellington", Required: true},
+			},
+			Extras: []hmc.Select{
+				{Name: "sauce", Required: true, Options: []hmc.Option{{Value: "bbq"}}},
+				{Name: "side", Required: true, Options: []hmc.Option{{Value: "chips", Selected: true}}},
+			},
+			Leftovers: hmc.Map{Name: "misc", Errors: []string{"too much"}},
+		},
+	}
+
+	ok, failures := f.Validate()
+
+	assert.Eq(t, "form is invalid", false, ok)
+	assert.SlicesEq(t, "failures in declaration order", []hmc.FieldError{
+		{Name: "name", Message: `"name" is required`},
+		{Name: "street", Message: `"street" requires at least 3 characters (currently 1 characters)`},
+		{Name: "sauce", Message: `"sauce" is required`},
+		{Name: "misc", Message: "too much"},
+	}, failures)
+	assert.Eq(t, "errors are left on controls", `"name" is required`, f.Elements.Name.FirstError())
+	assert.Eq(t, "errors are left on selects", `"sauce" is required`, f.Elements.Extras[0].FirstError())
+}
+
+func TestFormValidateValid(t *testing.T) {
+	f := hmc.Form[order]{
+		Elements: order{
+			Name: hmc.Input{Name: "name", Value: "Jo", Required: true},
+		},
+	}
+
+	ok, failures := f.Validate()
+
+	assert.Eq(t, "form is valid", true, ok)
+	assert.Eq(t, "no failures", 0, len(failures))
+}
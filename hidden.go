@@ -0,0 +1,72 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+)
+
+// Hidden is analogous to HTML's <input type="hidden">. It is a value that
+// the client should submit with the form as it is, without showing it to
+// the user, such as the ID of the resource being edited.
+//
+// For tokens that protect against cross-site request forgery, use
+// [CSRFToken].
+type Hidden struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (Hidden) controlName() string { return "Hidden" }
+
+func (h *Hidden) scope(from, to string) { h.Name = rescopeName(h.Name, from, to) }
+
+func (h Hidden) addFormValues(values url.Values) { values.Add(h.Name, h.Value) }
+
+// UnmarshalJSON replaces h with the Hidden in data.
+func (h *Hidden) UnmarshalJSON(data []byte) error {
+	var j hiddenJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*h = Hidden(j)
+	return nil
+}
+
+// ExtractFormValue sets Value to the first value of Name in form, and
+// deletes that value from form.
+func (h *Hidden) ExtractFormValue(form url.Values) {
+	formValue, ok := form[h.Name]
+	if ok {
+		h.Value = formValue[0]
+		if len(formValue[1:]) > 0 {
+			form[h.Name] = formValue[1:]
+		} else {
+			delete(form, h.Name)
+		}
+	}
+}
+
+func (h Hidden) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Hidden"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: h.Name})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: h.Value})
+	return e.EncodeElement("", start)
+}
+
+// UnmarshalXML decodes a c:Hidden element as marshalled by
+// [Hidden.MarshalXML].
+func (h *Hidden) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*h = Hidden{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "name":
+			h.Name = a.Value
+		case "value":
+			h.Value = a.Value
+		}
+	}
+
+	return d.Skip()
+}
@@ -6,39 +6,73 @@ import (
 	"encoding/xml"
 	"fmt"
 	"net/url"
+	"strconv"
 )
 
 // Input describes a piece of data the server needs from the client,
 // including validation requirements.
 //
-// It is analogous to HTML's <input> and <textarea>.
+// It is analogous to HTML's <input>. For multi-line text, use [Textarea].
 //
 // IMPORTANT: Some fields are mutually-irrelevant; such as Options and MinLength,
 // but they are both kept in this struct for simplicity. It is not an error
 // to have them both set at the same time, but it is semantically incorrect
 // and may cause confusion.
+//
+// Pattern is a regular expression that the whole of Value must match, as
+// with HTML's pattern attribute. Title describes the expected format to
+// humans, and is included in the error message if Value doesn't match.
 type Input struct {
 	Label     string
 	Type      string
 	Name      string
 	Required  bool
 	Value     string
-	Error     string
+	Errors    []string
 	MinLength uint
 	MaxLength uint
 	Step      float32
 	Min       string
 	Max       string
+	Pattern   string
+	Title     string
 }
 
+// passwordMask is marshalled in place of the value of a password input, so
+// that it isn't echoed back to the client.
+const passwordMask = "********"
+
+func (Input) controlName() string { return "Input" }
+
+func (i *Input) scope(from, to string) { i.Name = rescopeName(i.Name, from, to) }
+
+func (i Input) addFormValues(values url.Values) { values.Add(i.Name, i.Value) }
+
 func (i Input) MarshalJSON() ([]byte, error) {
 	j := inputJson(i)
 	if j.Type == "password" && j.Value != "" {
-		j.Value = "********"
+		j.Value = passwordMask
 	}
 	return json.Marshal(j)
 }
 
+// UnmarshalJSON decodes an Input as marshalled by [Input.MarshalJSON],
+// replacing i, so that Errors and constraints that data omits are cleared.
+//
+// A masked password value is decoded as an empty Value, since the real
+// value was withheld.
+func (i *Input) UnmarshalJSON(data []byte) error {
+	var j inputJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*i = Input(j)
+	if i.Type == "password" && i.Value == passwordMask {
+		i.Value = ""
+	}
+	return nil
+}
+
 func (i Input) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "c:Input"
 
@@ -48,7 +82,7 @@ func (i Input) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: i.Type})
 	}
 	if i.Type == "password" && i.Value != "" {
-		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: "********"})
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: passwordMask})
 	} else {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: i.Value})
 	}
@@ -67,6 +101,12 @@ func (i Input) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	if i.Max != "" {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "max"}, Value: i.Max})
 	}
+	if i.Pattern != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "pattern"}, Value: i.Pattern})
+	}
+	if i.Title != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "title"}, Value: i.Title})
+	}
 	if i.Required {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "required"}, Value: "true"})
 	}
@@ -75,45 +115,191 @@ func (i Input) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return nil
 	}
 
-	if i.Error != "" {
-		errorStart := xml.StartElement{Name: xml.Name{Local: "c:Error"}}
-		if err := e.EncodeElement(i.Error, errorStart); err != nil {
-			return err
-		}
+	if err := encodeErrors(e, i.Errors); err != nil {
+		return err
 	}
 
 	return e.EncodeToken(start.End())
 }
 
-// Validate performs some basic checks on the value
-// of the input according to its settings.
+// UnmarshalXML decodes a c:Input element as marshalled by [Input.MarshalXML].
 //
-// [Input.Required], [Input.Max], [Input.Min], [Input.MaxLength], and [Input.MinLength] are checked, in that order. Similar to the minimal
-// checks that a browser would make for equivalent HTML.
+// A masked password value is decoded as an empty Value, since the real
+// value was withheld.
+func (i *Input) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*i = Input{}
+
+	for _, a := range start.Attr {
+		var err error
+		switch a.Name.Local {
+		case "label":
+			i.Label = a.Value
+		case "name":
+			i.Name = a.Value
+		case "type":
+			i.Type = a.Value
+		case "value":
+			i.Value = a.Value
+		case "minlength":
+			i.MinLength, err = parseUintAttr(a.Value)
+		case "maxlength":
+			i.MaxLength, err = parseUintAttr(a.Value)
+		case "step":
+			var step float64
+			step, err = strconv.ParseFloat(a.Value, 32)
+			i.Step = float32(step)
+		case "min":
+			i.Min = a.Value
+		case "max":
+			i.Max = a.Value
+		case "pattern":
+			i.Pattern = a.Value
+		case "title":
+			i.Title = a.Value
+		case "required":
+			i.Required = parseBoolAttr(a.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("c:Input %s attribute: %w", a.Name.Local, err)
+		}
+	}
+
+	if i.Type == "password" && i.Value == passwordMask {
+		i.Value = ""
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Error") {
+			return decodeError(d, child, &i.Errors)
+		}
+		return d.Skip()
+	})
+}
+
+// Validate checks the value of the input against its settings, following
+// the constraint validation of the equivalent HTML so that the server agrees
+// with the browser.
+//
+// The checks are made in the same order as a browser makes them, and a
+// message for each that fails is appended to [Input.Errors]:
+//
+//   - [Input.Required]: an empty value is only invalid if the input is
+//     required. No other checks are made on an empty value.
+//   - [Input.Type]: number, range, date, time, datetime-local, month, week,
+//     email, url and color values must be in the format defined by HTML.
+//     Other types, such as tel and text, accept any value.
+//   - [Input.Pattern], which must match the whole value. It is ignored if
+//     it isn't a valid regular expression, or for types that don't accept
+//     free text.
+//   - [Input.MaxLength] and [Input.MinLength], counted in UTF-16 code units
+//     like a browser, for types that accept free text.
+//   - [Input.Min] and [Input.Max], compared as numbers, dates or times
+//     according to Type. They are ignored for types without a numeric
+//     interpretation, and if they can't be parsed.
+//   - [Input.Step], in the units of Type: e.g. days for date and seconds
+//     for time. Steps are counted from Min if set. If Step is zero the
+//     default step for Type is used.
 //
 // This functionality can be extended with more bespoke validation by
-// checking fields and setting the [Input.Error] field accordingly.
+// checking fields and appending to [Input.Errors] accordingly. The messages
+// of an earlier call to Validate are replaced, and other errors that are
+// already present are kept.
 func (p *Input) Validate() {
-	if p.Required && p.Value == "" {
-		p.Error = fmt.Sprintf("%#v is required", p.Name)
+	p.Errors = append(inputMessages.clear(p.Errors), p.validationMessages()...)
+}
+
+const (
+	msgRequired     = "%#v is required"
+	msgInvalidType  = "%#v must be %s"
+	msgPatternTitle = "%#v doesn't match the requested format: %s"
+	msgPattern      = "%#v doesn't match the requested format"
+	msgTooLong      = "%#v supports at most %d characters (currently %d characters)"
+	msgTooShort     = "%#v requires at least %d characters (currently %d characters)"
+	msgUnderflow    = "%#v must not be less than %#v"
+	msgOverflow     = "%#v must not be greater than %#v"
+	msgStepMismatch = "%#v is not a valid value; the nearest valid values are %#v and %#v"
+)
+
+var inputMessages = newMessageFormats(msgRequired, msgInvalidType, msgPatternTitle, msgPattern, msgTooLong, msgTooShort, msgUnderflow, msgOverflow, msgStepMismatch)
+
+func (p Input) validationMessages() []string {
+	if p.Value == "" {
+		if p.Required {
+			return []string{fmt.Sprintf(msgRequired, p.Name)}
+		}
+		return nil
 	}
 
-	if p.Max != "" && cmp.Less(p.Max, p.Value) {
-		p.Error = fmt.Sprintf("%#v must be less than %#v", p.Value, p.Max)
+	var msgs []string
+
+	if t, ok := patternTypes[p.Type]; ok && !t.valid(p.Value) {
+		msgs = append(msgs, fmt.Sprintf(msgInvalidType, p.Name, t.description))
+	}
+
+	if hasLength(p.Type) {
+		if p.Pattern != "" && !matchesPattern(p.Pattern, p.Value) {
+			if p.Title != "" {
+				msgs = append(msgs, fmt.Sprintf(msgPatternTitle, p.Name, p.Title))
+			} else {
+				msgs = append(msgs, fmt.Sprintf(msgPattern, p.Name))
+			}
+		}
+
+		valueLen := valueLength(p.Value)
+		if p.MaxLength > 0 && int(p.MaxLength) < valueLen {
+			msgs = append(msgs, fmt.Sprintf(msgTooLong, p.Name, p.MaxLength, valueLen))
+		}
+		if p.MinLength > 0 && int(p.MinLength) > valueLen {
+			msgs = append(msgs, fmt.Sprintf(msgTooShort, p.Name, p.MinLength, valueLen))
+		}
 	}
 
-	if p.Min != "" && cmp.Less(p.Value, p.Min) {
-		p.Error = fmt.Sprintf("%#v must be greater than %#v", p.Value, p.Max)
+	t, ok := numericTypes[p.Type]
+	if !ok {
+		return msgs
 	}
 
-	valueLen := len(p.Value)
-	if p.MinLength > 0 && int(p.MinLength) > valueLen {
-		p.Error = fmt.Sprintf("%#v requires at least %#v characters (currently %#v characters)", p.Name, p.MinLength, valueLen)
+	n, ok := t.parse(p.Value)
+	if !ok {
+		return append(msgs, fmt.Sprintf(msgInvalidType, p.Name, t.description))
 	}
 
-	if p.MaxLength > 0 && int(p.MaxLength) < valueLen {
-		p.Error = fmt.Sprintf("%#v supports at most %#v characters (currently %#v characters)", p.Name, p.MaxLength, valueLen)
+	minimum := cmp.Or(p.Min, t.defaultMin)
+	low, hasMin := t.parse(minimum)
+	if hasMin && n < low {
+		msgs = append(msgs, fmt.Sprintf(msgUnderflow, p.Value, minimum))
 	}
+
+	maximum := cmp.Or(p.Max, t.defaultMax)
+	if high, ok := t.parse(maximum); ok && n > high {
+		msgs = append(msgs, fmt.Sprintf(msgOverflow, p.Value, maximum))
+	}
+
+	step := t.defaultStep
+	if p.Step > 0 {
+		step = stepValue(p.Step)
+	}
+	base := t.stepBase
+	if hasMin {
+		base = low
+	}
+	if mismatch, below, above := stepMismatch(n, base, step*t.stepScale); mismatch {
+		msgs = append(msgs, fmt.Sprintf(msgStepMismatch, p.Value, t.format(below), t.format(above)))
+	}
+
+	return msgs
+}
+
+// FirstError returns the first of [Input.Errors], or "" if there are none.
+//
+// It is a convenience for templates that only show one error at a time.
+func (i Input) FirstError() string {
+	return firstError(i.Errors)
+}
+
+// FieldErrors reports each of [Input.Errors].
+func (i Input) FieldErrors() []FieldError {
+	return fieldErrors(i.Name, i.Errors)
 }
 
 // ValueFromUrlValues will searching for the Input's value under
@@ -0,0 +1,100 @@
+package hmc_test
+
+import (
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func TestInputValidate(t *testing.T) {
+	for _, c := range []struct {
+		context  string
+		input    hmc.Input
+		expected string
+	}{
+		{"empty optional", hmc.Input{Name: "n", Type: "number", MinLength: 3}, ""},
+		{"empty required", hmc.Input{Name: "n", Required: true}, `"n" is required`},
+		{"text ignores min", hmc.Input{Name: "n", Value: "b", Min: "c"}, ""},
+		{"too long", hmc.Input{Name: "n", Value: "abcd", MaxLength: 3}, `"n" supports at most 3 characters (currently 4 characters)`},
+		{"too short", hmc.Input{Name: "n", Value: "ab", MinLength: 3}, `"n" requires at least 3 characters (currently 2 characters)`},
+		{"length counts UTF-16", hmc.Input{Name: "n", Value: "😀", MaxLength: 1}, `"n" supports at most 1 characters (currently 2 characters)`},
+		{"pattern", hmc.Input{Name: "n", Value: "ab-12", Pattern: `[a-z]+-\d+`}, ""},
+		{"pattern is anchored", hmc.Input{Name: "n", Value: "ab-12x", Pattern: `[a-z]+-\d+|ab`}, `"n" doesn't match the requested format`},
+		{"pattern with title", hmc.Input{Name: "n", Value: "AB", Pattern: `[a-z]+`, Title: "lowercase letters"}, `"n" doesn't match the requested format: lowercase letters`},
+		{"pattern before length", hmc.Input{Name: "n", Value: "AB", Pattern: `[a-z]+`, MinLength: 3}, `"n" doesn't match the requested format`},
+		{"invalid pattern is ignored", hmc.Input{Name: "n", Value: "AB", Pattern: `[a-z`}, ""},
+		{"pattern ignored for numbers", hmc.Input{Name: "n", Type: "number", Value: "1", Pattern: `[a-z]+`}, ""},
+		{"tel accepts anything", hmc.Input{Name: "n", Type: "tel", Value: "call me"}, ""},
+
+		{"number", hmc.Input{Name: "n", Type: "number", Value: "-1.5e2", Step: 0.5}, ""},
+		{"not a number", hmc.Input{Name: "n", Type: "number", Value: "1,000"}, `"n" must be a number`},
+		{"number with plus", hmc.Input{Name: "n", Type: "number", Value: "+1"}, `"n" must be a number`},
+		{"numbers compare numerically", hmc.Input{Name: "n", Type: "number", Value: "9", Max: "10"}, ""},
+		{"number over max", hmc.Input{Name: "n", Type: "number", Value: "11", Max: "10"}, `"11" must not be greater than "10"`},
+		{"number under min", hmc.Input{Name: "n", Type: "number", Value: "-1", Min: "0"}, `"-1" must not be less than "0"`},
+		{"default step", hmc.Input{Name: "n", Type: "number", Value: "1.5"}, `"1.5" is not a valid value; the nearest valid values are "1" and "2"`},
+		{"decimal step", hmc.Input{Name: "n", Type: "number", Value: "0.3", Step: 0.1}, ""},
+		{"step from min", hmc.Input{Name: "n", Type: "number", Value: "4", Min: "1", Step: 2}, `"4" is not a valid value; the nearest valid values are "3" and "5"`},
+		{"invalid min is ignored", hmc.Input{Name: "n", Type: "number", Value: "3", Min: "x", Step: 2}, `"3" is not a valid value; the nearest valid values are "2" and "4"`},
+		{"range default max", hmc.Input{Name: "n", Type: "range", Value: "101"}, `"101" must not be greater than "100"`},
+
+		{"date", hmc.Input{Name: "n", Type: "date", Value: "2024-02-29", Min: "2024-01-01"}, ""},
+		{"invalid date", hmc.Input{Name: "n", Type: "date", Value: "2023-02-29"}, `"n" must be a date like 2006-01-02`},
+		{"date before min", hmc.Input{Name: "n", Type: "date", Value: "2023-12-31", Min: "2024-01-01"}, `"2023-12-31" must not be less than "2024-01-01"`},
+		{"date step", hmc.Input{Name: "n", Type: "date", Value: "2024-01-03", Min: "2024-01-01", Step: 7}, `"2024-01-03" is not a valid value; the nearest valid values are "2024-01-01" and "2024-01-08"`},
+
+		{"time", hmc.Input{Name: "n", Type: "time", Value: "09:30", Max: "17:00"}, ""},
+		{"time with seconds off default step", hmc.Input{Name: "n", Type: "time", Value: "09:30:15"}, `"09:30:15" is not a valid value; the nearest valid values are "09:30" and "09:31"`},
+		{"time after max", hmc.Input{Name: "n", Type: "time", Value: "17:01", Max: "17:00"}, `"17:01" must not be greater than "17:00"`},
+		{"invalid time", hmc.Input{Name: "n", Type: "time", Value: "25:00"}, `"n" must be a time like 15:04 or 15:04:05`},
+
+		{"datetime-local", hmc.Input{Name: "n", Type: "datetime-local", Value: "2024-01-01 09:30"}, ""},
+		{"invalid datetime-local", hmc.Input{Name: "n", Type: "datetime-local", Value: "2024-01-01"}, `"n" must be a date and time like 2006-01-02T15:04`},
+		{"datetime-local step", hmc.Input{Name: "n", Type: "datetime-local", Value: "2024-01-01T09:30", Step: 3600}, `"2024-01-01T09:30" is not a valid value; the nearest valid values are "2024-01-01T09:00" and "2024-01-01T10:00"`},
+
+		{"month", hmc.Input{Name: "n", Type: "month", Value: "2024-03", Min: "2024-01", Step: 2}, ""},
+		{"month step", hmc.Input{Name: "n", Type: "month", Value: "2024-02", Min: "2024-01", Step: 2}, `"2024-02" is not a valid value; the nearest valid values are "2024-01" and "2024-03"`},
+
+		{"week", hmc.Input{Name: "n", Type: "week", Value: "2020-W53"}, ""},
+		{"week that doesn't exist", hmc.Input{Name: "n", Type: "week", Value: "2021-W53"}, `"n" must be a week like 2006-W01`},
+		{"week after max", hmc.Input{Name: "n", Type: "week", Value: "2024-W10", Max: "2024-W09"}, `"2024-W10" must not be greater than "2024-W09"`},
+		{"week step", hmc.Input{Name: "n", Type: "week", Value: "2024-W02", Step: 2}, `"2024-W02" is not a valid value; the nearest valid values are "2024-W01" and "2024-W03"`},
+
+		{"email", hmc.Input{Name: "n", Type: "email", Value: "jo@example.com"}, ""},
+		{"invalid email", hmc.Input{Name: "n", Type: "email", Value: "jo@"}, `"n" must be an email address`},
+		{"url", hmc.Input{Name: "n", Type: "url", Value: "https://example.com"}, ""},
+		{"relative url", hmc.Input{Name: "n", Type: "url", Value: "/example"}, `"n" must be an absolute URL`},
+		{"color", hmc.Input{Name: "n", Type: "color", Value: "#FF8800"}, ""},
+		{"invalid color", hmc.Input{Name: "n", Type: "color", Value: "orange"}, `"n" must be a colour like #ff8800`},
+	} {
+		c.input.Validate()
+		assert.Eq(t, c.context, c.expected, c.input.FirstError())
+	}
+}
+
+func TestInputValidateCollectsErrors(t *testing.T) {
+	input := hmc.Input{
+		Name:      "email",
+		Type:      "email",
+		Value:     "NOT AN EMAIL",
+		Pattern:   `[a-z@.]+`,
+		MaxLength: 5,
+		Errors:    []string{"already taken"},
+	}
+
+	input.Validate()
+
+	assert.SlicesEq(t, "all failures are kept", []string{
+		"already taken",
+		`"email" must be an email address`,
+		`"email" doesn't match the requested format`,
+		`"email" supports at most 5 characters (currently 12 characters)`,
+	}, input.Errors)
+	assert.Eq(t, "first error", "already taken", input.FirstError())
+
+	input.Value = "a@b.c"
+	input.Validate()
+
+	assert.SlicesEq(t, "earlier failures are replaced", []string{"already taken"}, input.Errors)
+}
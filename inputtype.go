@@ -0,0 +1,269 @@
+package hmc
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// numericType describes how an <input> type with a numeric interpretation
+// is converted to and from a number for range and step validation, after
+// the HTML Living Standard.
+type numericType struct {
+	// description completes the sentence "the value must be ...".
+	description string
+	parse       func(string) (float64, bool)
+	format      func(float64) string
+	// stepScale converts [Input.Step] into the units of parse.
+	stepScale   float64
+	defaultStep float64
+	// stepBase is used to check step conformance when [Input.Min] isn't
+	// set.
+	stepBase   float64
+	defaultMin string
+	defaultMax string
+}
+
+const (
+	msPerSecond = 1000
+	msPerDay    = 24 * 60 * 60 * msPerSecond
+	msPerWeek   = 7 * msPerDay
+)
+
+var numericTypes = map[string]numericType{
+	"number": {
+		description: "a number",
+		parse:       parseNumber,
+		format:      formatNumber,
+		stepScale:   1,
+		defaultStep: 1,
+	},
+	"range": {
+		description: "a number",
+		parse:       parseNumber,
+		format:      formatNumber,
+		stepScale:   1,
+		defaultStep: 1,
+		defaultMin:  "0",
+		defaultMax:  "100",
+	},
+	"date": {
+		description: "a date like 2006-01-02",
+		parse:       parseTimeLayouts("2006-01-02"),
+		format:      formatTimeLayout("2006-01-02"),
+		stepScale:   msPerDay,
+		defaultStep: 1,
+	},
+	"time": {
+		description: "a time like 15:04 or 15:04:05",
+		parse:       parseTimeOfDay,
+		format:      formatTimeOfDay,
+		stepScale:   msPerSecond,
+		defaultStep: 60,
+	},
+	"datetime-local": {
+		description: "a date and time like 2006-01-02T15:04",
+		parse:       parseDatetimeLocal,
+		format:      formatDatetimeLocal,
+		stepScale:   msPerSecond,
+		defaultStep: 60,
+	},
+	"month": {
+		description: "a month like 2006-01",
+		parse:       parseMonth,
+		format:      formatMonth,
+		stepScale:   1,
+		defaultStep: 1,
+	},
+	"week": {
+		description: "a week like 2006-W01",
+		parse:       parseWeek,
+		format:      formatWeek,
+		stepScale:   msPerWeek,
+		defaultStep: 1,
+		// Monday of 1970-W01
+		stepBase: -3 * msPerDay,
+	},
+}
+
+// patternTypes are <input> types whose value must match a particular
+// format, but which have no numeric interpretation.
+var patternTypes = map[string]struct {
+	description string
+	valid       func(string) bool
+}{
+	"email": {"an email address", validEmail},
+	"url":   {"an absolute URL", validAbsoluteURL},
+	"color": {"a colour like #ff8800", validColor},
+}
+
+// matchesPattern reports whether the whole of value matches pattern, as
+// HTML's pattern attribute requires. Invalid patterns match anything, as
+// they do in browsers.
+func matchesPattern(pattern, value string) bool {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return true
+	}
+	return re.MatchString(value)
+}
+
+// hasLength reports whether minlength, maxlength and pattern apply to
+// inputs of type t. Unknown types are treated as text, as they are by
+// browsers.
+func hasLength(t string) bool {
+	_, numeric := numericTypes[t]
+	return !numeric && t != "color"
+}
+
+// valueLength counts UTF-16 code units, as browsers do for minlength and
+// maxlength.
+func valueLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+var floatingPointNumber = regexp.MustCompile(`^-?(?:[0-9]+(?:\.[0-9]+)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?$`)
+
+func parseNumber(s string) (float64, bool) {
+	if !floatingPointNumber.MatchString(s) {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil && !math.IsInf(n, 0)
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func parseTimeLayouts(layouts ...string) func(string) (float64, bool) {
+	return func(s string) (float64, bool) {
+		for _, layout := range layouts {
+			t, err := time.Parse(layout, s)
+			if err == nil {
+				return float64(t.UnixMilli()), true
+			}
+		}
+		return 0, false
+	}
+}
+
+func formatTimeLayout(layout string) func(float64) string {
+	return func(ms float64) string {
+		return time.UnixMilli(int64(ms)).UTC().Format(layout)
+	}
+}
+
+var parseTimeOfDayLayouts = parseTimeLayouts("15:04", "15:04:05")
+
+func parseTimeOfDay(s string) (float64, bool) {
+	ms, ok := parseTimeOfDayLayouts(s)
+	if !ok {
+		return 0, false
+	}
+	// time.Parse puts times without dates on 0000-01-01
+	return ms - float64(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()), true
+}
+
+func formatTimeOfDay(ms float64) string {
+	return formatDatetimeLocal(ms)[len("1970-01-01T"):]
+}
+
+var parseDatetimeLocalLayouts = parseTimeLayouts("2006-01-02T15:04", "2006-01-02T15:04:05")
+
+func parseDatetimeLocal(s string) (float64, bool) {
+	return parseDatetimeLocalLayouts(strings.Replace(s, " ", "T", 1))
+}
+
+func formatDatetimeLocal(ms float64) string {
+	t := time.UnixMilli(int64(ms)).UTC()
+	switch {
+	case t.Nanosecond() != 0:
+		return t.Format("2006-01-02T15:04:05.000")
+	case t.Second() != 0:
+		return t.Format("2006-01-02T15:04:05")
+	default:
+		return t.Format("2006-01-02T15:04")
+	}
+}
+
+func parseMonth(s string) (float64, bool) {
+	t, err := time.Parse("2006-01", s)
+	if err != nil {
+		return 0, false
+	}
+	return float64((t.Year()-1970)*12 + int(t.Month()) - 1), true
+}
+
+func formatMonth(months float64) string {
+	return time.Date(1970, time.Month(months)+1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01")
+}
+
+var weekPattern = regexp.MustCompile(`^([0-9]{4,})-W([0-9]{2})$`)
+
+func parseWeek(s string) (float64, bool) {
+	m := weekPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	year, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	week, _ := strconv.Atoi(m[2])
+	if _, weeks := time.Date(year, 12, 28, 0, 0, 0, 0, time.UTC).ISOWeek(); week < 1 || week > weeks {
+		return 0, false
+	}
+	// 4 January is always in the first week of the year
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7+(week-1)*7)
+	return float64(monday.UnixMilli()), true
+}
+
+func formatWeek(ms float64) string {
+	year, week := time.UnixMilli(int64(ms)).UTC().ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// emailPattern is the definition of a valid email address from the HTML
+// Living Standard.
+var emailPattern = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+
+func validEmail(s string) bool {
+	return emailPattern.MatchString(s)
+}
+
+func validAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func validColor(s string) bool {
+	return colorPattern.MatchString(s)
+}
+
+// stepValue converts step to a float64 by way of its shortest decimal
+// representation, so that e.g. a Step of 0.1 is treated as exactly 0.1 and
+// not 0.100000001490116.
+func stepValue(step float32) float64 {
+	n, _ := strconv.ParseFloat(strconv.FormatFloat(float64(step), 'g', -1, 32), 64)
+	return n
+}
+
+// stepMismatch reports whether n is not an integral number of steps from
+// base, and if so the nearest valid values either side of it.
+func stepMismatch(n, base, step float64) (bool, float64, float64) {
+	steps := (n - base) / step
+	if math.Abs(steps-math.Round(steps)) < 1e-9 {
+		return false, 0, 0
+	}
+	below := base + math.Floor(steps)*step
+	return true, below, below + step
+}
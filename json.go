@@ -1,15 +1,93 @@
 package hmc
 
+import "encoding/json"
+
 type inputJson struct {
-	Label     string  `json:"label"`
-	Type      string  `json:"type,omitempty"`
-	Name      string  `json:"name"`
-	Required  bool    `json:"required,omitempty"`
-	Value     string  `json:"value"`
-	Error     string  `json:"error,omitempty"`
-	MinLength uint    `json:"minlength,omitempty"`
-	MaxLength uint    `json:"maxlength,omitempty"`
-	Step      float32 `json:"step,omitempty"`
-	Min       string  `json:"min,omitempty"`
-	Max       string  `json:"max,omitempty"`
+	Label     string   `json:"label"`
+	Type      string   `json:"type,omitempty"`
+	Name      string   `json:"name"`
+	Required  bool     `json:"required,omitempty"`
+	Value     string   `json:"value"`
+	Errors    []string `json:"errors,omitempty"`
+	MinLength uint     `json:"minlength,omitempty"`
+	MaxLength uint     `json:"maxlength,omitempty"`
+	Step      float32  `json:"step,omitempty"`
+	Min       string   `json:"min,omitempty"`
+	Max       string   `json:"max,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Title     string   `json:"title,omitempty"`
+}
+
+// selectJson is the JSON shape of a Select, whose Groups are listed among
+// its options.
+type selectJson struct {
+	Multiple   bool              `json:"multiple,omitempty"`
+	Exhaustive bool              `json:"exhaustive,omitempty"`
+	Label      string            `json:"label"`
+	Name       string            `json:"name"`
+	Required   bool              `json:"required,omitempty"`
+	Options    []selectEntryJson `json:"options"`
+	Source     *OptionSource     `json:"source,omitempty"`
+	Errors     []string          `json:"errors,omitempty"`
+}
+
+// selectEntryJson is an entry of the options of a selectJson: either an
+// Option, or an OptGroup, which has options of its own.
+type selectEntryJson struct {
+	option *Option
+	group  *OptGroup
 }
+
+func (e selectEntryJson) MarshalJSON() ([]byte, error) {
+	if e.group != nil {
+		g := *e.group
+		if g.Options == nil {
+			// An empty group must still have "options" to be told apart
+			// from an option.
+			g.Options = []Option{}
+		}
+		return json.Marshal(g)
+	}
+	return json.Marshal(e.option)
+}
+
+func (e *selectEntryJson) UnmarshalJSON(data []byte) error {
+	var j struct {
+		optionJson
+		Options *[]Option `json:"options"`
+	}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.Options != nil {
+		e.group = &OptGroup{Label: j.Label, Disabled: j.Disabled, Options: *j.Options}
+	} else {
+		o := Option(j.optionJson)
+		e.option = &o
+	}
+	return nil
+}
+
+// The following types have the same fields and JSON tags as the controls
+// they are converted from, but none of their methods, so that encoding/json
+// can be used to implement those methods without recursing.
+type (
+	optionJson          Option
+	optGroupJson        OptGroup
+	mapJson             Map
+	linkJson            Link
+	optionSourceJson    OptionSource
+	optionListJson      OptionList
+	formJson[T any]     Form[T]
+	listJson[T any]     List[T]
+	fieldsetJson[T any] Fieldset[T]
+	submitJson          Submit
+	checkboxJson        Checkbox
+	radioGroupJson      RadioGroup
+	textareaJson        Textarea
+	hiddenJson          Hidden
+	csrfTokenJson       CSRFToken
+	fileJson            File
+	linkTemplateJson    LinkTemplate
+	paginationJson      Pagination
+)
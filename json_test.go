@@ -0,0 +1,107 @@
+package hmc_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func roundTripJson[T any](t *testing.T, expected T) {
+	t.Helper()
+
+	data, err := json.Marshal(expected)
+	assert.FatalErr(t, "marshalling", err)
+
+	var actual T
+	err = json.Unmarshal(data, &actual)
+	assert.FatalErr(t, "unmarshalling", err)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("round trip through %s:\n%#v\n!=\n%#v", data, expected, actual)
+	}
+}
+
+func TestJsonRoundTripInput(t *testing.T) {
+	roundTripJson(t, hmc.Input{
+		Label:     "Age",
+		Type:      "number",
+		Name:      "age",
+		Required:  true,
+		Value:     "30",
+		Errors:    []string{"too old", "too young"},
+		MinLength: 1,
+		MaxLength: 3,
+		Step:      0.5,
+		Min:       "0",
+		Max:       "29",
+	})
+}
+
+func TestJsonUnmarshalPasswordIsWithheld(t *testing.T) {
+	data, err := json.Marshal(hmc.Input{Name: "pw", Type: "password", Value: "hunter2"})
+	assert.FatalErr(t, "marshalling", err)
+
+	var input hmc.Input
+	err = json.Unmarshal(data, &input)
+	assert.FatalErr(t, "unmarshalling", err)
+
+	assert.Eq(t, "masked value is withheld", "", input.Value)
+	assert.Eq(t, "type", "password", input.Type)
+}
+
+func TestJsonUnmarshalResetsOmittedFields(t *testing.T) {
+	s := hmc.Select{Name: "old", Required: true, Multiple: true, Errors: []string{"stale"}}
+	err := json.Unmarshal([]byte(`{"label":"Size","name":"size","options":[{"value":"lg"}]}`), &s)
+	assert.FatalErr(t, "unmarshalling select", err)
+	assert.Eq(t, "required is reset", false, s.Required)
+	assert.Eq(t, "multiple is reset", false, s.Multiple)
+	assert.Eq(t, "errors are reset", 0, len(s.Errors))
+
+	m := hmc.Map{Entries: map[string][]string{"stale": {"1"}}}
+	err = json.Unmarshal([]byte(`{"label":"Misc","name":"misc","entries":{"iq":["80"]}}`), &m)
+	assert.FatalErr(t, "unmarshalling map", err)
+	assert.Eq(t, "entries are replaced", 1, len(m.Entries))
+
+	i := hmc.Input{Name: "old", Required: true, MaxLength: 3, Errors: []string{"stale"}}
+	err = json.Unmarshal([]byte(`{"label":"Age","name":"age","value":"30"}`), &i)
+	assert.FatalErr(t, "unmarshalling input", err)
+	assert.True(t, "input is replaced", reflect.DeepEqual(hmc.Input{Label: "Age", Name: "age", Value: "30"}, i))
+
+	o := hmc.Option{Value: "lg", Selected: true, Disabled: true}
+	err = json.Unmarshal([]byte(`{"value":"sm"}`), &o)
+	assert.FatalErr(t, "unmarshalling option", err)
+	assert.Eq(t, "option is replaced", hmc.Option{Value: "sm"}, o)
+
+	l := hmc.Link{Label: "Old", Href: "/old", Rel: "prev", Method: "POST"}
+	err = json.Unmarshal([]byte(`{"label":"Next","href":"/next"}`), &l)
+	assert.FatalErr(t, "unmarshalling link", err)
+	assert.Eq(t, "link is replaced", hmc.Link{Label: "Next", Href: "/next"}, l)
+
+	f := hmc.Form[search]{Method: "POST", Elements: search{Query: hmc.Input{Name: "q", Errors: []string{"stale"}}}}
+	err = json.Unmarshal([]byte(`{"elements":{"Query":{"label":"Query","name":"q","value":"shoes"}}}`), &f)
+	assert.FatalErr(t, "unmarshalling form", err)
+	assert.Eq(t, "method is reset", "", f.Method)
+	assert.True(t, "elements are replaced", reflect.DeepEqual(hmc.Input{Label: "Query", Name: "q", Value: "shoes"}, f.Elements.Query))
+}
+
+func TestJsonRoundTripForm(t *testing.T) {
+	roundTripJson(t, hmc.Form[order]{
+		Method:  "POST",
+		Action:  "/orders",
+		Enctype: hmc.EnctypeJSON,
+		Elements: order{
+			Name: hmc.Input{Label: "Name", Name: "name", Value: "Jo"},
+			Address: address{
+				Street: hmc.Input{Name: "street", Value: "1 Main St"},
+				City:   &hmc.Input{Name: "city", Errors: []string{"unknown city"}},
+			},
+			Extras: []hmc.Select{
+				{Name: "sauce", Multiple: true, Options: []hmc.Option{{Value: "bbq", Selected: true, Disabled: true}}},
+			},
+			Leftovers: hmc.Map{Label: "Notes", Entries: map[string][]string{"a": {"b"}}},
+		},
+	})
+}
@@ -1,18 +1,71 @@
 package hmc
 
-import "encoding/xml"
+import (
+	"encoding/json"
+	"encoding/xml"
+	"slices"
+	"strings"
+)
 
 // Link represents a state transition that requires no input—a simple
 // navigation or action trigger.
+//
+// Rel is a space-separated list of the link's relation types, as defined
+// by RFC 8288 and as in HTML's rel attribute, such as "next", "edit" or
+// "self", so that clients can find a link by what it is for rather than by
+// its Label. Relation types are compared without regard to case.
+//
+// Type is the media type that the client can expect when following the
+// link, such as "text/csv", and Hreflang the language of the target, both
+// as hints. Title describes the target, as in HTML.
+//
+// Method is the HTTP method to follow the link with, GET by default, for
+// action triggers that need no input.
 type Link struct {
-	Label string `json:"label"`
-	Href  string `json:"href"`
+	Label    string `json:"label"`
+	Href     string `json:"href"`
+	Rel      string `json:"rel,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Hreflang string `json:"hreflang,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Method   string `json:"method,omitempty"`
+}
+
+// HasRel reports whether rel is among the link's relation types.
+func (i Link) HasRel(rel string) bool {
+	return slices.ContainsFunc(strings.Fields(i.Rel), func(r string) bool { return strings.EqualFold(r, rel) })
+}
+
+// UnmarshalJSON replaces i with the Link in data, clearing whichever of
+// Rel, Type, Hreflang, Title and Method data omits.
+func (i *Link) UnmarshalJSON(data []byte) error {
+	var j linkJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*i = Link(j)
+	return nil
 }
 
 func (i Link) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name = xml.Name{Local: "c:Link"}
 
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "href"}, Value: i.Href})
+	if i.Rel != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "rel"}, Value: i.Rel})
+	}
+	if i.Type != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: i.Type})
+	}
+	if i.Hreflang != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "hreflang"}, Value: i.Hreflang})
+	}
+	if i.Title != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "title"}, Value: i.Title})
+	}
+	if i.Method != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "method"}, Value: i.Method})
+	}
 
 	if err := e.EncodeToken(start); err != nil {
 		return err
@@ -24,3 +77,29 @@ func (i Link) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 	return e.EncodeToken(start.End())
 }
+
+func (Link) controlName() string { return "Link" }
+
+// UnmarshalXML decodes a c:Link element as marshalled by [Link.MarshalXML].
+func (i *Link) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*i = Link{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "href":
+			i.Href = a.Value
+		case "rel":
+			i.Rel = a.Value
+		case "type":
+			i.Type = a.Value
+		case "hreflang":
+			i.Hreflang = a.Value
+		case "title":
+			i.Title = a.Value
+		case "method":
+			i.Method = a.Value
+		}
+	}
+
+	return d.DecodeElement(&i.Label, &start)
+}
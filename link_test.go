@@ -0,0 +1,18 @@
+package hmc_test
+
+import (
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func TestLinkHasRel(t *testing.T) {
+	l := hmc.Link{Label: "Next", Href: "/page/2", Rel: "next  prefetch"}
+
+	assert.True(t, "next", l.HasRel("next"))
+	assert.True(t, "prefetch", l.HasRel("prefetch"))
+	assert.True(t, "case is ignored", l.HasRel("NEXT"))
+	assert.True(t, "prev", !l.HasRel("prev"))
+	assert.True(t, "empty", !l.HasRel(""))
+}
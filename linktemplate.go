@@ -0,0 +1,200 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrTemplateVariableMissing is returned by [LinkTemplate.Expand] if a
+// required variable has no value.
+var ErrTemplateVariableMissing = errors.New("a required template variable is missing")
+
+// LinkTemplate is a [Link] whose Href is a URI template, as defined by
+// RFC 6570, such as "/orders/{id}" or "/search{?q,page}". The client
+// expands it with values of its choosing to get the link's target, so that
+// a parameterised link needn't be expanded in advance for every value.
+//
+// Variables describe the template's variables for the client. They needn't
+// describe every variable in Href, but a client can only be expected to
+// give values for those that they do.
+//
+// Rel, Type, Title and Method are as for [Link].
+type LinkTemplate struct {
+	Label     string             `json:"label"`
+	Href      string             `json:"href"`
+	Rel       string             `json:"rel,omitempty"`
+	Type      string             `json:"type,omitempty"`
+	Title     string             `json:"title,omitempty"`
+	Method    string             `json:"method,omitempty"`
+	Variables []TemplateVariable `json:"variables,omitempty"`
+}
+
+// TemplateVariable describes a variable of a [LinkTemplate]. A Required
+// variable must be given a value when the template is expanded.
+type TemplateVariable struct {
+	Label    string `json:"label"`
+	Name     string `json:"name"`
+	Required bool   `json:"required,omitempty"`
+}
+
+func (LinkTemplate) controlName() string { return "LinkTemplate" }
+
+// HasRel reports whether rel is among the link's relation types, as with
+// [Link.HasRel].
+func (l LinkTemplate) HasRel(rel string) bool {
+	return Link{Rel: l.Rel}.HasRel(rel)
+}
+
+// Variable returns the description of the variable named name.
+func (l LinkTemplate) Variable(name string) (TemplateVariable, bool) {
+	i := slices.IndexFunc(l.Variables, func(v TemplateVariable) bool { return v.Name == name })
+	if i < 0 {
+		return TemplateVariable{}, false
+	}
+	return l.Variables[i], true
+}
+
+// Expand expands Href with values, as [ExpandURITemplate] does. It returns
+// an error wrapping [ErrTemplateVariableMissing] if any of the Required
+// Variables is undefined in values.
+func (l LinkTemplate) Expand(values map[string]any) (string, error) {
+	var missing []string
+	for _, v := range l.Variables {
+		if _, ok := uriValue(values[v.Name]); v.Required && !ok {
+			missing = append(missing, fmt.Sprintf("%#v", v.Name))
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrTemplateVariableMissing, strings.Join(missing, ", "))
+	}
+	return ExpandURITemplate(l.Href, values)
+}
+
+// Link expands the template with values, as [LinkTemplate.Expand] does,
+// and returns the resulting [Link].
+func (l LinkTemplate) Link(values map[string]any) (Link, error) {
+	href, err := l.Expand(values)
+	if err != nil {
+		return Link{}, err
+	}
+	return Link{
+		Label:  l.Label,
+		Href:   href,
+		Rel:    l.Rel,
+		Type:   l.Type,
+		Title:  l.Title,
+		Method: l.Method,
+	}, nil
+}
+
+// UnmarshalJSON replaces l with the LinkTemplate in data, so its Variables
+// are exactly those listed in data.
+func (l *LinkTemplate) UnmarshalJSON(data []byte) error {
+	var j linkTemplateJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*l = LinkTemplate(j)
+	return nil
+}
+
+// MarshalXML marshals the LinkTemplate as a c:LinkTemplate element, with
+// a c:Variable child for each of its Variables. Unlike [Link.MarshalXML],
+// Label is an attribute.
+func (l LinkTemplate) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:LinkTemplate"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: l.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "href"}, Value: l.Href})
+	if l.Rel != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "rel"}, Value: l.Rel})
+	}
+	if l.Type != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: l.Type})
+	}
+	if l.Title != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "title"}, Value: l.Title})
+	}
+	if l.Method != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "method"}, Value: l.Method})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, v := range l.Variables {
+		if err := e.Encode(v); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:LinkTemplate element as marshalled by
+// [LinkTemplate.MarshalXML].
+func (l *LinkTemplate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*l = LinkTemplate{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			l.Label = a.Value
+		case "href":
+			l.Href = a.Value
+		case "rel":
+			l.Rel = a.Value
+		case "type":
+			l.Type = a.Value
+		case "title":
+			l.Title = a.Value
+		case "method":
+			l.Method = a.Value
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Variable") {
+			var v TemplateVariable
+			if err := d.DecodeElement(&v, &child); err != nil {
+				return err
+			}
+			l.Variables = append(l.Variables, v)
+			return nil
+		}
+		return d.Skip()
+	})
+}
+
+func (v TemplateVariable) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Variable"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: v.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: v.Name})
+	if v.Required {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "required"}, Value: "true"})
+	}
+	return e.EncodeElement("", start)
+}
+
+// UnmarshalXML decodes a c:Variable element as marshalled by
+// [TemplateVariable.MarshalXML].
+func (v *TemplateVariable) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*v = TemplateVariable{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			v.Label = a.Value
+		case "name":
+			v.Name = a.Value
+		case "required":
+			v.Required = parseBoolAttr(a.Value)
+		}
+	}
+
+	return d.Skip()
+}
@@ -0,0 +1,60 @@
+package hmc_test
+
+import (
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func newOrderSearch() hmc.LinkTemplate {
+	return hmc.LinkTemplate{
+		Label: "Search orders",
+		Href:  "/orders{?q,status*,page}",
+		Rel:   "search",
+		Variables: []hmc.TemplateVariable{
+			{Label: "Query", Name: "q", Required: true},
+			{Label: "Status", Name: "status"},
+			{Label: "Page", Name: "page"},
+		},
+	}
+}
+
+func TestLinkTemplateExpand(t *testing.T) {
+	l := newOrderSearch()
+
+	for _, c := range []struct {
+		name     string
+		values   map[string]any
+		expected string
+		err      error
+	}{
+		{"every variable", map[string]any{"q": "blue shoes", "status": []string{"paid", "sent"}, "page": 2}, "/orders?q=blue%20shoes&status=paid&status=sent&page=2", nil},
+		{"empty query", map[string]any{"q": ""}, "/orders?q=", nil},
+		{"missing query", map[string]any{"page": 2}, "", hmc.ErrTemplateVariableMissing},
+	} {
+		href, err := l.Expand(c.values)
+		assert.FatalErrIs(t, c.name, err, c.err)
+		assert.Eq(t, c.name, c.expected, href)
+	}
+}
+
+func TestLinkTemplateLink(t *testing.T) {
+	l := hmc.LinkTemplate{Label: "Order", Href: "/orders/{id}", Rel: "item", Method: "GET"}
+
+	link, err := l.Link(map[string]any{"id": "A/1"})
+	assert.FatalErr(t, "expanding", err)
+	assert.Eq(t, "link", hmc.Link{Label: "Order", Href: "/orders/A%2F1", Rel: "item", Method: "GET"}, link)
+	assert.True(t, "rel", l.HasRel("ITEM"))
+}
+
+func TestLinkTemplateVariable(t *testing.T) {
+	l := newOrderSearch()
+
+	v, ok := l.Variable("status")
+	assert.True(t, "found status", ok)
+	assert.Eq(t, "status", hmc.TemplateVariable{Label: "Status", Name: "status"}, v)
+
+	_, ok = l.Variable("sort")
+	assert.True(t, "sort not found", !ok)
+}
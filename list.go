@@ -0,0 +1,280 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// List is a repeating group of controls, for inputs like the lines of an
+// order, to which the client may add as many items as it needs.
+//
+// T is the group of controls in each item, usually a struct of [Input] and
+// [Select] controls. Prototype is a T with no values, whose controls are
+// named relative to the item. In each of Items, they are scoped to the
+// item's index in bracket notation, so that in the first item of a List
+// named "lines", a control named "sku" is named "lines[0][sku]".
+//
+// Items should be added with [List.Add], which copies and scopes the
+// Prototype.
+type List[T any] struct {
+	Label     string   `json:"label"`
+	Name      string   `json:"name"`
+	MinItems  uint     `json:"minitems,omitempty"`
+	MaxItems  uint     `json:"maxitems,omitempty"`
+	Prototype T        `json:"prototype"`
+	Items     []T      `json:"items"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+func (List[T]) controlName() string { return "List" }
+
+// UnmarshalJSON replaces l with the List in data, rather than decoding the
+// items into those l already has, which would leave fields of the old
+// items behind wherever data omits them.
+func (l *List[T]) UnmarshalJSON(data []byte) error {
+	var j listJson[T]
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*l = List[T](j)
+	return nil
+}
+
+func (l List[T]) addFormValues(values url.Values) {
+	for i := range l.Items {
+		for name, vs := range formValues(reflect.ValueOf(&l.Items[i]).Elem()) {
+			values[name] = append(values[name], vs...)
+		}
+	}
+}
+
+func (l *List[T]) scope(from, to string) {
+	old := l.Name
+	l.Name = rescopeName(l.Name, from, to)
+	for i := range l.Items {
+		scopeNames(reflect.ValueOf(&l.Items[i]).Elem(), old, l.Name)
+	}
+}
+
+// itemName returns the prefix of the names of item i.
+func (l List[T]) itemName(i int) string {
+	return fmt.Sprintf("%s[%d]", l.Name, i)
+}
+
+// Next returns a copy of the Prototype scoped to the index of the item
+// after the last of Items. It is what a new item added by the client would
+// look like.
+func (l List[T]) Next() T {
+	item := deepCopy(reflect.ValueOf(&l.Prototype).Elem())
+	scopeNames(item, "", l.itemName(len(l.Items)))
+	return item.Interface().(T)
+}
+
+// Add appends [List.Next] to Items, and returns a pointer to it so that its
+// values can be set.
+func (l *List[T]) Add() *T {
+	l.Items = append(l.Items, l.Next())
+	return &l.Items[len(l.Items)-1]
+}
+
+// ExtractFormValue replaces Items with the items in form, which are named
+// with their index in bracket notation, e.g. "lines[3][sku]".
+//
+// Items are added in order of their index, and renumbered from 0. Indexes
+// needn't be consecutive, so a client may number new items however it
+// likes. An item whose values are all empty or the same as the
+// Prototype's, such as the blank item rendered for adding another, is left
+// out.
+//
+// If form has no items at all, the list wasn't submitted, and Items is
+// left as it is, e.g. prefilled for a form that hasn't been filled in yet.
+// A client that removes every item still submits the blank item, which
+// empties Items.
+func (l *List[T]) ExtractFormValue(form url.Values) {
+	list := Map{Name: l.Name}
+	items := map[int]url.Values{}
+	for name, values := range form {
+		path, ok := list.Path(name)
+		if !ok || len(path) < 2 {
+			continue
+		}
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 {
+			continue
+		}
+		delete(form, name)
+		if items[i] == nil {
+			items[i] = url.Values{}
+		}
+		items[i][Map{}.NamedKey(path[1:]...)] = values
+	}
+
+	indexes := make([]int, 0, len(items))
+	for i := range items {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	if len(indexes) == 0 {
+		return
+	}
+
+	defaults := formValues(reflect.ValueOf(&l.Prototype).Elem())
+	l.Items = nil
+	for _, i := range indexes {
+		if isBlank(items[i], defaults) {
+			continue
+		}
+		item := deepCopy(reflect.ValueOf(&l.Prototype).Elem())
+		extractFormValues(item, items[i])
+		scopeNames(item, "", l.itemName(len(l.Items)))
+		l.Items = append(l.Items, item.Interface().(T))
+	}
+}
+
+// ExtractFormFiles does nothing, since the items of a List are renumbered
+// by [List.ExtractFormValue], so uploaded files can't be matched to them.
+// It keeps any [File] in the Prototype from taking files meant for other
+// controls.
+func (l *List[T]) ExtractFormFiles(files map[string][]*multipart.FileHeader) {}
+
+// isBlank reports whether every value in form is empty, or is the value
+// that the control would have by default.
+func isBlank(form, defaults url.Values) bool {
+	for name, values := range form {
+		if slices.Equal(values, defaults[name]) {
+			continue
+		}
+		for _, v := range values {
+			if v != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Validate checks the number of Items against MinItems and MaxItems,
+// appending any problem to [List.Errors], and then validates the controls
+// of each item as [Form.Validate] does.
+//
+// The messages of an earlier call to Validate are replaced, and other
+// errors that are already present are kept.
+func (l *List[T]) Validate() {
+	l.Errors = listMessages.clear(l.Errors)
+
+	n := len(l.Items)
+	if l.MaxItems > 0 && int(l.MaxItems) < n {
+		l.Errors = append(l.Errors, fmt.Sprintf(msgTooManyItems, l.Name, l.MaxItems, n))
+	}
+	if l.MinItems > 0 && int(l.MinItems) > n {
+		l.Errors = append(l.Errors, fmt.Sprintf(msgTooFewItems, l.Name, l.MinItems, n))
+	}
+
+	for i := range l.Items {
+		validateControls(reflect.ValueOf(&l.Items[i]).Elem())
+	}
+}
+
+const (
+	msgTooManyItems = "%#v supports at most %d items (currently %d items)"
+	msgTooFewItems  = "%#v requires at least %d items (currently %d items)"
+)
+
+var listMessages = newMessageFormats(msgTooManyItems, msgTooFewItems)
+
+// FirstError returns the first of [List.Errors], or "" if there are none.
+func (l List[T]) FirstError() string {
+	return firstError(l.Errors)
+}
+
+// FieldErrors reports each of [List.Errors], followed by the errors of the
+// controls of each item.
+func (l List[T]) FieldErrors() []FieldError {
+	errs := fieldErrors(l.Name, l.Errors)
+	for i := range l.Items {
+		errs = append(errs, controlErrors(reflect.ValueOf(&l.Items[i]).Elem())...)
+	}
+	return errs
+}
+
+// MarshalXML marshals the list as a c:List element, with each of Items as
+// a c:Item element and the Prototype as a c:Prototype element.
+func (l List[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:List"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: l.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: l.Name})
+	if l.MinItems > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "minitems"}, Value: fmt.Sprintf("%d", l.MinItems)})
+	}
+	if l.MaxItems > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxitems"}, Value: fmt.Sprintf("%d", l.MaxItems)})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, item := range l.Items {
+		if err := e.EncodeElement(item, xml.StartElement{Name: xml.Name{Local: "c:Item"}}); err != nil {
+			return err
+		}
+	}
+
+	if err := e.EncodeElement(l.Prototype, xml.StartElement{Name: xml.Name{Local: "c:Prototype"}}); err != nil {
+		return err
+	}
+
+	if err := encodeErrors(e, l.Errors); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:List element as marshalled by [List.MarshalXML].
+// The controls of each item are decoded as those of [Form.Elements] are.
+func (l *List[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*l = List[T]{}
+
+	var err error
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			l.Label = a.Value
+		case "name":
+			l.Name = a.Value
+		case "minitems":
+			l.MinItems, err = parseUintAttr(a.Value)
+		case "maxitems":
+			l.MaxItems, err = parseUintAttr(a.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("c:List %s attribute: %w", a.Name.Local, err)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch {
+		case isControlElement(child.Name, "Item"):
+			var item T
+			if err := decodeValue(d, reflect.ValueOf(&item).Elem(), child); err != nil {
+				return err
+			}
+			l.Items = append(l.Items, item)
+			return nil
+		case isControlElement(child.Name, "Prototype"):
+			return decodeValue(d, reflect.ValueOf(&l.Prototype).Elem(), child)
+		case isControlElement(child.Name, "Error"):
+			return decodeError(d, child, &l.Errors)
+		default:
+			return d.Skip()
+		}
+	})
+}
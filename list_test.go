@@ -0,0 +1,120 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type line struct {
+	SKU      hmc.Input
+	Quantity hmc.Input
+	Gift     hmc.Select
+}
+
+func lines() hmc.List[line] {
+	return hmc.List[line]{
+		Label:    "Order lines",
+		Name:     "lines",
+		MinItems: 1,
+		MaxItems: 2,
+		Prototype: line{
+			SKU:      hmc.Input{Label: "SKU", Name: "sku", Required: true},
+			Quantity: hmc.Input{Label: "Quantity", Name: "qty", Type: "number", Min: "1"},
+			Gift: hmc.Select{Label: "Gift wrap", Name: "gift", Options: []hmc.Option{
+				{Value: "no", Selected: true}, {Value: "yes"},
+			}},
+		},
+	}
+}
+
+func TestListAdd(t *testing.T) {
+	l := lines()
+	l.Add().SKU.Value = "A1"
+	second := l.Add()
+	second.Gift.SetValues("yes")
+
+	assert.Eq(t, "first item is scoped", "lines[0][sku]", l.Items[0].SKU.Name)
+	assert.Eq(t, "second item is scoped", "lines[1][qty]", l.Items[1].Quantity.Name)
+	assert.Eq(t, "value is set", "A1", l.Items[0].SKU.Value)
+	assert.Eq(t, "first item's options are its own", "no", l.Items[0].Gift.Value())
+	assert.Eq(t, "second item's options are its own", "yes", l.Items[1].Gift.Value())
+	assert.Eq(t, "prototype is unchanged", "sku", l.Prototype.SKU.Name)
+	assert.Eq(t, "prototype's options are unchanged", "no", l.Prototype.Gift.Value())
+	assert.Eq(t, "next item", "lines[2][sku]", l.Next().SKU.Name)
+}
+
+func TestListExtractFormValue(t *testing.T) {
+	l := lines()
+	form := url.Values{
+		"lines[5][sku]":  {"B2"},
+		"lines[5][qty]":  {"3"},
+		"lines[5][gift]": {"yes"},
+		"lines[0][sku]":  {"A1"},
+		"lines[0][qty]":  {"1"},
+		"lines[9][sku]":  {""},
+		"lines[9][qty]":  {""},
+		"lines[9][gift]": {"no"},
+		"lines[x][sku]":  {"?"},
+		"sku":            {"not in the list"},
+	}
+	l.ExtractFormValue(form)
+
+	assert.Eq(t, "blank item is left out", 2, len(l.Items))
+	assert.Eq(t, "items are in order of index", "A1", l.Items[0].SKU.Value)
+	assert.Eq(t, "items are renumbered", "lines[1][sku]", l.Items[1].SKU.Name)
+	assert.Eq(t, "values are extracted", "3", l.Items[1].Quantity.Value)
+	assert.Eq(t, "selects are extracted", "yes", l.Items[1].Gift.Value())
+	assert.Eq(t, "default selection is kept", "no", l.Items[0].Gift.Value())
+	assert.Eq(t, "other entries are left", 2, len(form))
+
+	ok, failures := (&hmc.Form[hmc.List[line]]{Elements: l}).Validate()
+	assert.Eq(t, "valid", true, ok)
+	assert.Eq(t, "no failures", 0, len(failures))
+}
+
+func TestListExtractNoItems(t *testing.T) {
+	l := lines()
+	l.Add().SKU.Value = "A1"
+	l.ExtractFormValue(url.Values{"sku": {"not in the list"}})
+
+	assert.Eq(t, "prefilled items are kept", 1, len(l.Items))
+	assert.Eq(t, "prefilled value is kept", "A1", l.Items[0].SKU.Value)
+
+	l.ExtractFormValue(url.Values{"lines[1][sku]": {""}, "lines[1][gift]": {"no"}})
+
+	assert.Eq(t, "every item is removed", 0, len(l.Items))
+}
+
+func TestListValidate(t *testing.T) {
+	f := hmc.Form[hmc.List[line]]{Elements: lines()}
+	f.ExtractFormValue(url.Values{
+		"lines[0][sku]": {""},
+		"lines[0][qty]": {"0"},
+		"lines[1][sku]": {"B2"},
+		"lines[2][sku]": {"C3"},
+	})
+
+	ok, failures := f.Validate()
+
+	assert.Eq(t, "invalid", false, ok)
+	assert.SlicesEq(t, "failures", []hmc.FieldError{
+		{Name: "lines", Message: `"lines" supports at most 2 items (currently 3 items)`},
+		{Name: "lines[0][sku]", Message: `"lines[0][sku]" is required`},
+		{Name: "lines[0][qty]", Message: `"0" must not be less than "1"`},
+	}, failures)
+
+	f = hmc.Form[hmc.List[line]]{Elements: lines()}
+	f.ExtractFormValue(url.Values{"lines[0][sku]": {""}})
+	_, failures = f.Validate()
+	assert.SlicesEq(t, "too few", []hmc.FieldError{
+		{Name: "lines", Message: `"lines" requires at least 1 items (currently 0 items)`},
+	}, failures)
+
+	f.Elements.Add().SKU.Value = "A1"
+	ok, failures = f.Validate()
+	assert.Eq(t, "valid again", true, ok)
+	assert.Eq(t, "earlier failures are replaced", 0, len(failures))
+}
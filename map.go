@@ -1,25 +1,193 @@
 package hmc
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"iter"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
 )
 
+// Map accepts arbitrary name-value pairs under Name, in bracket notation,
+// e.g. "data[colour]=blue". Each key is an entry, which may have several
+// values.
+//
+// Keys may be nested, as in "filters[price][min]=5", in which case the
+// entry is kept in a Map in Nested. Empty keys are kept as they are, so
+// "tags[]=a&tags[]=b" is the entry "" with the values "a" and "b".
+//
+// The keys and values that may be given can be constrained, and are checked
+// by [Map.Validate].
 type Map struct {
-	Label   string              `json:"label"`
-	Name    string              `json:"name"`
-	Entries map[string][]string `json:"entries"`
-	Error   string              `json:"error,omitempty"`
+	Label string `json:"label"`
+	Name  string `json:"name"`
+	// KeyPattern, if set, is a regular expression that must match the whole
+	// of each key.
+	KeyPattern string `json:"keypattern,omitempty"`
+	// Keys, if set, are the only keys that may be given. In XML they are
+	// separated by spaces, so they mustn't contain any.
+	Keys       []string `json:"keys,omitempty"`
+	MinEntries uint     `json:"minentries,omitempty"`
+	MaxEntries uint     `json:"maxentries,omitempty"`
+	// MinLength and MaxLength limit the length of each non-empty value,
+	// counted as for [Input.MinLength] and [Input.MaxLength].
+	MinLength uint                `json:"minlength,omitempty"`
+	MaxLength uint                `json:"maxlength,omitempty"`
+	Entries   map[string][]string `json:"entries"`
+	// Nested holds the entries with more than one key, by their first key.
+	// Each is named after the path to it, e.g. "filters[price]".
+	Nested map[string]*Map `json:"nested,omitempty"`
+	Errors []string        `json:"errors,omitempty"`
+	// KeyErrors holds the errors of individual entries, by key.
+	KeyErrors map[string][]string `json:"keyerrors,omitempty"`
 }
 
-func (m Map) NamedKey(key string) string {
+func (Map) controlName() string { return "Map" }
+
+func (m *Map) scope(from, to string) {
+	m.Name = rescopeName(m.Name, from, to)
+	for _, n := range m.Nested {
+		n.scope(from, to)
+	}
+}
+
+func (m Map) addFormValues(values url.Values) {
+	for name, vs := range m.All() {
+		values[name] = append(values[name], vs...)
+	}
+}
+
+// UnmarshalJSON replaces m with the Map in data. Decoding into m's own
+// Entries would add the decoded entries to those it already has.
+func (m *Map) UnmarshalJSON(data []byte) error {
+	var j mapJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*m = Map(j)
+	return nil
+}
+
+// NamedKey returns the name of the entry at the path keys, in bracket
+// notation, e.g. "filters[price][min]" for the keys "price" and "min" of a
+// Map named "filters". An empty key is an array, as in "tags[]".
+//
+// If the Map has no Name, the first key stands on its own, e.g. "price[min]".
+func (m Map) NamedKey(keys ...string) string {
+	var sb strings.Builder
+	sb.WriteString(m.Name)
+	for i, k := range keys {
+		if i == 0 && m.Name == "" {
+			sb.WriteString(k)
+		} else {
+			fmt.Fprintf(&sb, "[%s]", k)
+		}
+	}
+	return sb.String()
+}
+
+// Path is the inverse of [Map.NamedKey]. It splits name into the keys of
+// an entry, and reports false if name doesn't belong to this Map.
+//
+// A Map without a Name takes every name: one that isn't in bracket
+// notation, such as "a[b", is a single key of its own.
+func (m Map) Path(name string) ([]string, bool) {
+	var path []string
+	rest, ok := strings.CutPrefix(name, m.Name)
 	if m.Name == "" {
-		return key
-	} else {
-		return fmt.Sprintf("%s[%s]", m.Name, key)
+		first, after, found := strings.Cut(name, "[")
+		if !found {
+			return []string{name}, true
+		}
+		path = append(path, first)
+		rest = "[" + after
+	} else if !ok || rest == "" {
+		return nil, false
+	}
+
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			if m.Name == "" {
+				return []string{name}, true
+			}
+			return nil, false
+		}
+		path = append(path, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return path, true
+}
+
+// Key is the inverse of [Map.NamedKey] for entries with a single key. It
+// reports false if name doesn't belong to this Map, or is nested.
+func (m Map) Key(name string) (string, bool) {
+	path, ok := m.Path(name)
+	if !ok || len(path) != 1 {
+		return "", false
+	}
+	return path[0], true
+}
+
+// Get returns the values of the entry at path, looking in [Map.Nested] for
+// paths of more than one key.
+func (m Map) Get(path ...string) []string {
+	switch len(path) {
+	case 0:
+		return nil
+	case 1:
+		return m.Entries[path[0]]
+	}
+	n, ok := m.Nested[path[0]]
+	if !ok {
+		return nil
+	}
+	return n.Get(path[1:]...)
+}
+
+// Set sets the values of the entry at path, adding to [Map.Nested] as
+// needed for paths of more than one key.
+func (m *Map) Set(path []string, values ...string) {
+	switch len(path) {
+	case 0:
+		return
+	case 1:
+		if m.Entries == nil {
+			m.Entries = make(map[string][]string)
+		}
+		m.Entries[path[0]] = values
+		return
+	}
+	if m.Nested == nil {
+		m.Nested = make(map[string]*Map)
+	}
+	n, ok := m.Nested[path[0]]
+	if !ok {
+		n = &Map{Name: m.NamedKey(path[0])}
+		m.Nested[path[0]] = n
+	}
+	n.Set(path[1:], values...)
+}
+
+// All yields the name and values of each entry, including those in
+// [Map.Nested], in order of their keys.
+func (m Map) All() iter.Seq2[string, []string] {
+	return func(yield func(string, []string) bool) {
+		for _, k := range sortedKeys(m.Entries) {
+			if !yield(m.NamedKey(k), m.Entries[k]) {
+				return
+			}
+		}
+		for _, k := range sortedKeys(m.Nested) {
+			for name, values := range m.Nested[k].All() {
+				if !yield(name, values) {
+					return
+				}
+			}
+		}
 	}
 }
 
@@ -28,41 +196,157 @@ func (m *Map) ExtractFormValue(form url.Values) {
 		m.Entries = make(map[string][]string, len(form))
 	}
 	for k, v := range form {
-		var key string
-		if m.Name != "" {
-			after, ok := strings.CutPrefix(k, m.Name+"[")
-			if !ok {
-				continue
+		path, ok := m.Path(k)
+		if !ok {
+			continue
+		}
+		delete(form, k)
+		m.Set(path, v...)
+	}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keys returns the first key of each entry, nested or not, in order.
+func (m Map) keys() []string {
+	keys := sortedKeys(m.Entries)
+	for k := range m.Nested {
+		if _, ok := m.Entries[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate checks the entries against the Map's constraints. Problems with
+// the number of entries are appended to [Map.Errors], and problems with an
+// individual entry are appended to its key in [Map.KeyErrors]:
+//
+//   - [Map.MinEntries] and [Map.MaxEntries] limit the number of keys.
+//   - Each key must be one of [Map.Keys], if there are any, and must match
+//     [Map.KeyPattern], if it is set and valid.
+//   - Each non-empty value, nested or not, must be within [Map.MinLength]
+//     and [Map.MaxLength].
+//
+// Keys are checked at the top level only; a nested entry is reported
+// under its first key.
+//
+// The messages of an earlier call to Validate are replaced, and other
+// errors that are already present are kept.
+func (m *Map) Validate() {
+	m.Errors = mapMessages.clear(m.Errors)
+	for k, msgs := range m.KeyErrors {
+		if msgs = mapMessages.clear(msgs); len(msgs) > 0 {
+			m.KeyErrors[k] = msgs
+		} else {
+			delete(m.KeyErrors, k)
+		}
+	}
+
+	keys := m.keys()
+	n := len(keys)
+	if m.MaxEntries > 0 && int(m.MaxEntries) < n {
+		m.Errors = append(m.Errors, fmt.Sprintf(msgTooManyEntries, m.Name, m.MaxEntries, n))
+	}
+	if m.MinEntries > 0 && int(m.MinEntries) > n {
+		m.Errors = append(m.Errors, fmt.Sprintf(msgTooFewEntries, m.Name, m.MinEntries, n))
+	}
+
+	for _, k := range keys {
+		var msgs []string
+		if len(m.Keys) > 0 && !slices.Contains(m.Keys, k) {
+			msgs = append(msgs, fmt.Sprintf(msgUnknownKey, k, m.Name))
+		} else if m.KeyPattern != "" && !matchesPattern(m.KeyPattern, k) {
+			msgs = append(msgs, fmt.Sprintf(msgInvalidKey, k, m.Name))
+		}
+		entry := Map{Name: m.Name, Entries: map[string][]string{k: m.Entries[k]}}
+		if n, ok := m.Nested[k]; ok {
+			entry.Nested = map[string]*Map{k: n}
+		}
+		for name, values := range entry.All() {
+			for _, v := range values {
+				if v == "" {
+					continue
+				}
+				valueLen := valueLength(v)
+				if m.MaxLength > 0 && int(m.MaxLength) < valueLen {
+					msgs = append(msgs, fmt.Sprintf(msgTooLong, name, m.MaxLength, valueLen))
+				}
+				if m.MinLength > 0 && int(m.MinLength) > valueLen {
+					msgs = append(msgs, fmt.Sprintf(msgTooShort, name, m.MinLength, valueLen))
+				}
 			}
-			key, ok = strings.CutSuffix(after, "]")
-			if !ok {
-				continue
+		}
+		if len(msgs) > 0 {
+			if m.KeyErrors == nil {
+				m.KeyErrors = make(map[string][]string)
 			}
-		} else {
-			key = k
+			m.KeyErrors[k] = append(m.KeyErrors[k], msgs...)
 		}
-		delete(form, k)
-		m.Entries[key] = v
 	}
 }
 
+const (
+	msgTooManyEntries = "%#v supports at most %d entries (currently %d entries)"
+	msgTooFewEntries  = "%#v requires at least %d entries (currently %d entries)"
+	msgUnknownKey     = "%#v is not one of the keys of %#v"
+	msgInvalidKey     = "%#v is not a valid key for %#v"
+)
+
+var mapMessages = newMessageFormats(msgTooManyEntries, msgTooFewEntries, msgUnknownKey, msgInvalidKey, msgTooLong, msgTooShort)
+
+// FirstError returns the first of [Map.Errors], or "" if there are none.
+func (m Map) FirstError() string {
+	return firstError(m.Errors)
+}
+
+// FieldErrors reports each of [Map.Errors], followed by each of
+// [Map.KeyErrors] under the name of its entry.
+func (m Map) FieldErrors() []FieldError {
+	errs := fieldErrors(m.Name, m.Errors)
+	for _, k := range sortedKeys(m.KeyErrors) {
+		errs = append(errs, fieldErrors(m.NamedKey(k), m.KeyErrors[k])...)
+	}
+	return errs
+}
+
 func (m Map) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "c:Map"
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: m.Label})
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: m.Name})
+	if m.KeyPattern != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "keypattern"}, Value: m.KeyPattern})
+	}
+	if len(m.Keys) > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "keys"}, Value: strings.Join(m.Keys, " ")})
+	}
+	if m.MinEntries > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "minentries"}, Value: fmt.Sprintf("%d", m.MinEntries)})
+	}
+	if m.MaxEntries > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxentries"}, Value: fmt.Sprintf("%d", m.MaxEntries)})
+	}
+	if m.MinLength > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "minlength"}, Value: fmt.Sprintf("%d", m.MinLength)})
+	}
+	if m.MaxLength > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxlength"}, Value: fmt.Sprintf("%d", m.MaxLength)})
+	}
 
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
 
-	keys := make([]string, 0, len(m.Entries))
-	for k := range m.Entries {
-		keys = append(keys, k)
-	}
-
-	sort.Strings(keys)
-
-	for _, k := range keys {
+	for _, k := range sortedKeys(m.Entries) {
 		for _, v := range m.Entries[k] {
 			keyElem := xml.StartElement{Name: xml.Name{Local: "c:Input"}}
 			keyElem.Attr = append(keyElem.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: m.NamedKey(k)})
@@ -73,5 +357,106 @@ func (m Map) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		}
 	}
 
+	for _, k := range sortedKeys(m.Nested) {
+		if err := e.Encode(m.Nested[k]); err != nil {
+			return err
+		}
+	}
+
+	if err := encodeErrors(e, m.Errors); err != nil {
+		return err
+	}
+
+	for _, k := range sortedKeys(m.KeyErrors) {
+		errorStart := xml.StartElement{Name: xml.Name{Local: "c:Error"}}
+		errorStart.Attr = append(errorStart.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: m.NamedKey(k)})
+		for _, msg := range m.KeyErrors[k] {
+			if err := e.EncodeElement(msg, errorStart); err != nil {
+				return err
+			}
+		}
+	}
+
 	return e.EncodeToken(start.End())
 }
+
+// UnmarshalXML decodes a c:Map element as marshalled by [Map.MarshalXML].
+func (m *Map) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*m = Map{}
+
+	var err error
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			m.Label = a.Value
+		case "name":
+			m.Name = a.Value
+		case "keypattern":
+			m.KeyPattern = a.Value
+		case "keys":
+			m.Keys = strings.Fields(a.Value)
+		case "minentries":
+			m.MinEntries, err = parseUintAttr(a.Value)
+		case "maxentries":
+			m.MaxEntries, err = parseUintAttr(a.Value)
+		case "minlength":
+			m.MinLength, err = parseUintAttr(a.Value)
+		case "maxlength":
+			m.MaxLength, err = parseUintAttr(a.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("c:Map %s attribute: %w", a.Name.Local, err)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch {
+		case isControlElement(child.Name, "Input"):
+			var name, value string
+			for _, a := range child.Attr {
+				switch a.Name.Local {
+				case "name":
+					name = a.Value
+				case "value":
+					value = a.Value
+				}
+			}
+			if path, ok := m.Path(name); ok {
+				m.Set(path, append(m.Get(path...), value)...)
+			}
+			return d.Skip()
+		case isControlElement(child.Name, "Map"):
+			var n Map
+			if err := d.DecodeElement(&n, &child); err != nil {
+				return err
+			}
+			if key, ok := m.Key(n.Name); ok {
+				if m.Nested == nil {
+					m.Nested = make(map[string]*Map)
+				}
+				m.Nested[key] = &n
+			}
+			return nil
+		case isControlElement(child.Name, "Error"):
+			for _, a := range child.Attr {
+				if a.Name.Local != "name" {
+					continue
+				}
+				if key, ok := m.Key(a.Value); ok {
+					if m.KeyErrors == nil {
+						m.KeyErrors = make(map[string][]string)
+					}
+					msgs := m.KeyErrors[key]
+					if err := decodeError(d, child, &msgs); err != nil {
+						return err
+					}
+					m.KeyErrors[key] = msgs
+					return nil
+				}
+			}
+			return decodeError(d, child, &m.Errors)
+		default:
+			return d.Skip()
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func TestMapValidate(t *testing.T) {
+	for _, c := range []struct {
+		context   string
+		m         hmc.Map
+		form      url.Values
+		errors    []string
+		keyErrors map[string][]string
+	}{
+		{
+			"no constraints",
+			hmc.Map{Name: "labels"},
+			url.Values{"labels[anything goes]": {""}},
+			nil, nil,
+		},
+		{
+			"too few entries",
+			hmc.Map{Name: "labels", MinEntries: 2},
+			url.Values{"labels[a]": {"1"}},
+			[]string{`"labels" requires at least 2 entries (currently 1 entries)`}, nil,
+		},
+		{
+			"too many entries",
+			hmc.Map{Name: "labels", MaxEntries: 1},
+			url.Values{"labels[a]": {"1"}, "labels[b]": {"2"}},
+			[]string{`"labels" supports at most 1 entries (currently 2 entries)`}, nil,
+		},
+		{
+			"key not allowed",
+			hmc.Map{Name: "labels", Keys: []string{"env", "team"}},
+			url.Values{"labels[env]": {"prod"}, "labels[owner]": {"jo"}},
+			nil, map[string][]string{"owner": {`"owner" is not one of the keys of "labels"`}},
+		},
+		{
+			"key pattern",
+			hmc.Map{Name: "labels", KeyPattern: `[a-z][a-z0-9-]*`},
+			url.Values{"labels[app-1]": {"x"}, "labels[App 1]": {"y"}},
+			nil, map[string][]string{"App 1": {`"App 1" is not a valid key for "labels"`}},
+		},
+		{
+			"value lengths",
+			hmc.Map{Name: "labels", MinLength: 2, MaxLength: 4},
+			url.Values{"labels[a]": {"x", "okay", "too long", ""}},
+			nil, map[string][]string{"a": {
+				`"labels[a]" requires at least 2 characters (currently 1 characters)`,
+				`"labels[a]" supports at most 4 characters (currently 8 characters)`,
+			}},
+		},
+	} {
+		c.m.ExtractFormValue(c.form)
+		c.m.Validate()
+		assert.SlicesEq(t, c.context+": errors", c.errors, c.m.Errors)
+		assert.Eq(t, c.context+": number of keys with errors", len(c.keyErrors), len(c.m.KeyErrors))
+		for k, expected := range c.keyErrors {
+			assert.SlicesEq(t, c.context+": errors of "+k, expected, c.m.KeyErrors[k])
+		}
+	}
+}
+
+func TestMapFieldErrors(t *testing.T) {
+	m := hmc.Map{
+		Name:      "labels",
+		Errors:    []string{"too many"},
+		KeyErrors: map[string][]string{"b": {"bad b"}, "a": {"bad a"}},
+	}
+	assert.SlicesEq(t, "field errors", []hmc.FieldError{
+		{Name: "labels", Message: "too many"},
+		{Name: "labels[a]", Message: "bad a"},
+		{Name: "labels[b]", Message: "bad b"},
+	}, m.FieldErrors())
+}
+
+func TestMapPath(t *testing.T) {
+	for _, c := range []struct {
+		mapName string
+		name    string
+		path    []string
+		ok      bool
+	}{
+		{"filters", "filters[colour]", []string{"colour"}, true},
+		{"filters", "filters[price][min]", []string{"price", "min"}, true},
+		{"filters", "filters[tags][]", []string{"tags", ""}, true},
+		{"filters", "filters[]", []string{""}, true},
+		{"filters", "filters", nil, false},
+		{"filters", "filtersx[a]", nil, false},
+		{"filters", "filters[a]x", nil, false},
+		{"filters", "filters[a", nil, false},
+		{"", "colour", []string{"colour"}, true},
+		{"", "price[min]", []string{"price", "min"}, true},
+		{"", "tags[]", []string{"tags", ""}, true},
+		{"", "a[b", []string{"a[b"}, true},
+		{"", "a]", []string{"a]"}, true},
+		{"", "a[b]c", []string{"a[b]c"}, true},
+	} {
+		m := hmc.Map{Name: c.mapName}
+		path, ok := m.Path(c.name)
+		assert.Eq(t, c.name+" belongs to the map", c.ok, ok)
+		assert.SlicesEq(t, c.name+" path", c.path, path)
+		if ok {
+			assert.Eq(t, c.name+" round trip", c.name, m.NamedKey(path...))
+		}
+	}
+}
+
+func TestMapExtractNested(t *testing.T) {
+	m := hmc.Map{Name: "filters"}
+	form := url.Values{
+		"filters[colour]":     {"red"},
+		"filters[price][min]": {"5"},
+		"filters[price][max]": {"10"},
+		"filters[tags][]":     {"a", "b"},
+		"other[price][min]":   {"1"},
+	}
+	m.ExtractFormValue(form)
+
+	assert.SlicesEq(t, "top level entry", []string{"red"}, m.Get("colour"))
+	assert.SlicesEq(t, "nested entry", []string{"5"}, m.Get("price", "min"))
+	assert.SlicesEq(t, "array", []string{"a", "b"}, m.Get("tags", ""))
+	assert.Eq(t, "nested map name", "filters[price]", m.Nested["price"].Name)
+	assert.Eq(t, "unrelated entries are left", 1, len(form))
+
+	var names []string
+	for name := range m.All() {
+		names = append(names, name)
+	}
+	assert.SlicesEq(t, "all names", []string{
+		"filters[colour]",
+		"filters[price][max]",
+		"filters[price][min]",
+		"filters[tags][]",
+	}, names)
+}
+
+func TestMapExtractMalformedKeys(t *testing.T) {
+	m := hmc.Map{}
+	form := url.Values{
+		"colour":     {"red"},
+		"price[min]": {"5"},
+		"a[b":        {"1"},
+		"a]":         {"2"},
+	}
+	m.ExtractFormValue(form)
+
+	assert.SlicesEq(t, "malformed key", []string{"1"}, m.Get("a[b"))
+	assert.SlicesEq(t, "stray bracket", []string{"2"}, m.Get("a]"))
+	assert.SlicesEq(t, "nested entry", []string{"5"}, m.Get("price", "min"))
+	assert.Eq(t, "all entries are extracted", 0, len(form))
+}
+
+func TestMapValidateNested(t *testing.T) {
+	m := hmc.Map{Name: "filters", Keys: []string{"price"}, MaxEntries: 1, MaxLength: 3}
+	m.ExtractFormValue(url.Values{
+		"filters[price][min]": {"5"},
+		"filters[price][max]": {"1000"},
+		"filters[size][]":     {"xl"},
+	})
+	m.Validate()
+
+	assert.SlicesEq(t, "errors", []string{`"filters" supports at most 1 entries (currently 2 entries)`}, m.Errors)
+	assert.SlicesEq(t, "nested value errors", []string{`"filters[price][max]" supports at most 3 characters (currently 4 characters)`}, m.KeyErrors["price"])
+	assert.SlicesEq(t, "nested key errors", []string{`"size" is not one of the keys of "filters"`}, m.KeyErrors["size"])
+}
+
+func TestMapValidateAgain(t *testing.T) {
+	m := hmc.Map{Name: "filters", Keys: []string{"price"}, MaxEntries: 1}
+	m.ExtractFormValue(url.Values{
+		"filters[price]": {"5"},
+		"filters[size]":  {"xl"},
+	})
+	m.Validate()
+	m.Errors = append(m.Errors, "custom")
+	m.KeyErrors["price"] = []string{"too expensive"}
+	m.Validate()
+
+	assert.SlicesEq(t, "errors", []string{"custom", `"filters" supports at most 1 entries (currently 2 entries)`}, m.Errors)
+	assert.SlicesEq(t, "custom key errors", []string{"too expensive"}, m.KeyErrors["price"])
+	assert.SlicesEq(t, "key errors", []string{`"size" is not one of the keys of "filters"`}, m.KeyErrors["size"])
+
+	delete(m.Entries, "size")
+	m.KeyErrors = nil
+	m.Validate()
+
+	assert.SlicesEq(t, "errors after fixing", []string{"custom"}, m.Errors)
+	assert.Eq(t, "no key errors", 0, len(m.KeyErrors))
+}
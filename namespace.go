@@ -17,6 +17,21 @@ var docs xml.Comment
 
 const repo string = "github.com/Teajey/hmc"
 
+// NamespaceURL is the URL of the c: namespace that hypermedia controls are
+// in, as declared by [SetNamespace].
+const NamespaceURL string = "https://" + repo
+
+// ControlElementName returns the local name of name, such as "Form", if it
+// is the element of a hypermedia control, i.e. if it is in the c:
+// namespace. The c: prefix is only resolved to [NamespaceURL] by
+// encoding/xml if the document declares it, so both are accepted.
+func ControlElementName(name xml.Name) (string, bool) {
+	if name.Space == "c" || name.Space == NamespaceURL {
+		return name.Local, true
+	}
+	return "", false
+}
+
 func init() {
 	docs = xml.Comment(fmt.Sprintf("See an overview of what this XML means at https://%s/blob/main/README.md ", repo))
 }
@@ -24,7 +39,7 @@ func init() {
 // SetNamespace provides a default setting for the Namespace struct.
 func SetNamespace() Namespace {
 	return Namespace{
-		HcXmlns: "https://github.com/Teajey/hmc",
+		HcXmlns: NamespaceURL,
 		Docs:    docs,
 	}
 }
@@ -0,0 +1,260 @@
+package hmc
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OptionSource points to a resource that lists the options of a [Select],
+// for when there are too many options to include in the Select itself.
+//
+// The resource responds with an [OptionList]. Clients may filter it by
+// setting the query parameter named SearchParam to a search term, and page
+// through it with the query parameter named PageParam, counting from 1.
+type OptionSource struct {
+	Href        string `json:"href"`
+	SearchParam string `json:"search,omitempty"`
+	PageParam   string `json:"page,omitempty"`
+}
+
+// UnmarshalJSON replaces s with the OptionSource in data, so a search or
+// page parameter that data doesn't name falls back to its default.
+func (s *OptionSource) UnmarshalJSON(data []byte) error {
+	var j optionSourceJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = OptionSource(j)
+	return nil
+}
+
+func (s OptionSource) searchParam() string {
+	return cmp.Or(s.SearchParam, "q")
+}
+
+func (s OptionSource) pageParam() string {
+	return cmp.Or(s.PageParam, "page")
+}
+
+// URL returns the URL of the page of options matching search, resolved
+// against base, which should be the URL of the document the Select was
+// found in.
+func (s OptionSource) URL(base *url.URL, search string, page int) (*url.URL, error) {
+	href, err := url.Parse(s.Href)
+	if err != nil {
+		return nil, err
+	}
+	u := base.ResolveReference(href)
+	q := u.Query()
+	if search != "" {
+		q.Set(s.searchParam(), search)
+	}
+	if page > 1 {
+		q.Set(s.pageParam(), strconv.Itoa(page))
+	}
+	u.RawQuery = q.Encode()
+	return u, nil
+}
+
+// ParseQuery reads the search term and page number from the URL of a
+// request for options. The page is 1 if it is missing or invalid.
+func (s OptionSource) ParseQuery(u *url.URL) (search string, page int) {
+	q := u.Query()
+	page, err := strconv.Atoi(q.Get(s.pageParam()))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return q.Get(s.searchParam()), page
+}
+
+// List serves a request for options from a slice of all of them.
+//
+// The options whose label or value contains the search term in u,
+// ignoring case, are paged into pages of pageSize, and the page requested
+// in u is returned. Next and Prev are set to the URLs of the neighbouring
+// pages, relative to u. If pageSize isn't positive, every match is on the
+// first page.
+//
+// Options that are backed by a database should rather be filtered there,
+// using [OptionSource.ParseQuery] and [OptionSource.PageLinks].
+func (s OptionSource) List(u *url.URL, options []Option, pageSize int) OptionList {
+	search, page := s.ParseQuery(u)
+
+	var matches []Option
+	search = strings.ToLower(search)
+	for _, o := range options {
+		if strings.Contains(strings.ToLower(o.Label), search) || strings.Contains(strings.ToLower(o.Value), search) {
+			matches = append(matches, o)
+		}
+	}
+
+	// Pages past the end are empty. page is compared with the number of
+	// pages before multiplying, as a huge page would overflow.
+	start, end := len(matches), len(matches)
+	if pageSize <= 0 {
+		if page == 1 {
+			start = 0
+		}
+	} else if page <= pageCount(len(matches), pageSize) {
+		start = (page - 1) * pageSize
+		end = min(start+pageSize, len(matches))
+	}
+
+	list := OptionList{
+		Total:   len(matches),
+		Options: matches[start:end],
+	}
+	list.Prev, list.Next = s.PageLinks(u, page, pageSize, len(matches))
+	return list
+}
+
+// pageCount returns the number of pages of pageSize that total items fill.
+// pageSize must be positive.
+func pageCount(total, pageSize int) int {
+	pages := total / pageSize
+	if total%pageSize > 0 {
+		pages++
+	}
+	return pages
+}
+
+// PageLinks returns the URLs of the pages either side of page, relative to
+// u, for a search with total matches. Either is empty if there is no such
+// page. If pageSize isn't positive, every match is on the first page.
+func (s OptionSource) PageLinks(u *url.URL, page, pageSize, total int) (prev, next string) {
+	link := func(page int) string {
+		q := u.Query()
+		if page > 1 {
+			q.Set(s.pageParam(), strconv.Itoa(page))
+		} else {
+			q.Del(s.pageParam())
+		}
+		return relativeURL(u, q)
+	}
+	if page > 1 {
+		prev = link(page - 1)
+	}
+	if pageSize > 0 && page < pageCount(total, pageSize) {
+		next = link(page + 1)
+	}
+	return prev, next
+}
+
+func (s OptionSource) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:OptionSource"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "href"}, Value: s.Href})
+	if s.SearchParam != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "search"}, Value: s.SearchParam})
+	}
+	if s.PageParam != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "page"}, Value: s.PageParam})
+	}
+	return e.EncodeElement("", start)
+}
+
+// UnmarshalXML decodes a c:OptionSource element as marshalled by
+// [OptionSource.MarshalXML].
+func (s *OptionSource) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*s = OptionSource{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "href":
+			s.Href = a.Value
+		case "search":
+			s.SearchParam = a.Value
+		case "page":
+			s.PageParam = a.Value
+		}
+	}
+
+	return d.Skip()
+}
+
+// OptionList is a page of the options served by an [OptionSource].
+//
+// Total is the number of options matching the search across all pages.
+// Next and Prev link to the neighbouring pages, if there are any.
+type OptionList struct {
+	Total   int      `json:"total"`
+	Prev    string   `json:"prev,omitempty"`
+	Next    string   `json:"next,omitempty"`
+	Options []Option `json:"options"`
+}
+
+// UnmarshalJSON replaces l with the OptionList in data. A page without a
+// "prev" or "next" link leaves Prev or Next empty, even when decoding into
+// the list of another page.
+func (l *OptionList) UnmarshalJSON(data []byte) error {
+	var j optionListJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*l = OptionList(j)
+	return nil
+}
+
+// MarshalXML marshals the list as a c:OptionList element. Since an
+// OptionList is usually a document of its own, the c: namespace is
+// declared on it.
+func (l OptionList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:OptionList"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:c"}, Value: NamespaceURL})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "total"}, Value: strconv.Itoa(l.Total)})
+	if l.Prev != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "prev"}, Value: l.Prev})
+	}
+	if l.Next != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "next"}, Value: l.Next})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, o := range l.Options {
+		if err := e.Encode(o); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:OptionList element as marshalled by
+// [OptionList.MarshalXML].
+func (l *OptionList) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*l = OptionList{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "total":
+			total, err := strconv.Atoi(a.Value)
+			if err != nil {
+				return fmt.Errorf("c:OptionList total attribute: %w", err)
+			}
+			l.Total = total
+		case "prev":
+			l.Prev = a.Value
+		case "next":
+			l.Next = a.Value
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Option") {
+			var o Option
+			if err := d.DecodeElement(&o, &child); err != nil {
+				return err
+			}
+			l.Options = append(l.Options, o)
+			return nil
+		}
+		return d.Skip()
+	})
+}
@@ -0,0 +1,93 @@
+package hmc_test
+
+import (
+	"encoding/xml"
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func customers() []hmc.Option {
+	return []hmc.Option{
+		{Label: "Acme Ltd", Value: "c-1"},
+		{Label: "Bakery Co", Value: "c-2"},
+		{Label: "Acme Holdings", Value: "c-3"},
+		{Label: "Cartwright", Value: "c-4"},
+		{Label: "Acme Services", Value: "c-5"},
+	}
+}
+
+func TestOptionSourceURL(t *testing.T) {
+	base, _ := url.Parse("https://example.com/orders/new")
+	source := hmc.OptionSource{Href: "/customers?active=1"}
+
+	u, err := source.URL(base, "acme", 2)
+	assert.FatalErr(t, "building url", err)
+	assert.Eq(t, "url", "https://example.com/customers?active=1&page=2&q=acme", u.String())
+
+	u, err = source.URL(base, "", 1)
+	assert.FatalErr(t, "building url", err)
+	assert.Eq(t, "first page without search", "https://example.com/customers?active=1", u.String())
+
+	search, page := source.ParseQuery(u)
+	assert.Eq(t, "no search", "", search)
+	assert.Eq(t, "default page", 1, page)
+}
+
+func TestOptionSourceList(t *testing.T) {
+	source := hmc.OptionSource{Href: "/customers", SearchParam: "name", PageParam: "p"}
+
+	u, _ := url.Parse("/customers?name=ACME")
+	list := source.List(u, customers(), 2)
+	assert.Eq(t, "total", 3, list.Total)
+	assert.SlicesEq(t, "first page", []hmc.Option{
+		{Label: "Acme Ltd", Value: "c-1"},
+		{Label: "Acme Holdings", Value: "c-3"},
+	}, list.Options)
+	assert.Eq(t, "no prev", "", list.Prev)
+	assert.Eq(t, "next", "/customers?name=ACME&p=2", list.Next)
+
+	u, _ = url.Parse(list.Next)
+	list = source.List(u, customers(), 2)
+	assert.SlicesEq(t, "second page", []hmc.Option{
+		{Label: "Acme Services", Value: "c-5"},
+	}, list.Options)
+	assert.Eq(t, "prev", "/customers?name=ACME", list.Prev)
+	assert.Eq(t, "no next", "", list.Next)
+
+	u, _ = url.Parse("/customers?p=9")
+	list = source.List(u, customers(), 2)
+	assert.Eq(t, "total past the end", 5, list.Total)
+	assert.Eq(t, "no options past the end", 0, len(list.Options))
+
+	u, _ = url.Parse("/customers?p=9223372036854775807")
+	list = source.List(u, customers(), 2)
+	assert.Eq(t, "no options on a huge page", 0, len(list.Options))
+	assert.Eq(t, "no next after a huge page", "", list.Next)
+	assert.Eq(t, "prev before a huge page", "/customers?p=9223372036854775806", list.Prev)
+
+	for _, pageSize := range []int{0, -1} {
+		u, _ = url.Parse("/customers?name=acme")
+		list = source.List(u, customers(), pageSize)
+		assert.Eq(t, "one page of every match", 3, len(list.Options))
+		assert.Eq(t, "no next without a page size", "", list.Next)
+
+		prev, next := source.PageLinks(u, 1, pageSize, 3)
+		assert.Eq(t, "no prev link without a page size", "", prev)
+		assert.Eq(t, "no next link without a page size", "", next)
+	}
+}
+
+func TestOptionListRoundTrip(t *testing.T) {
+	u, _ := url.Parse("/customers?q=acme")
+	list := hmc.OptionSource{Href: "/customers"}.List(u, customers(), 2)
+
+	data, err := xml.Marshal(list)
+	assert.FatalErr(t, "marshalling", err)
+	assert.Eq(t, "xml", `<c:OptionList xmlns:c="https://github.com/Teajey/hmc" total="3" next="/customers?page=2&amp;q=acme"><c:Option value="c-1">Acme Ltd</c:Option><c:Option value="c-3">Acme Holdings</c:Option></c:OptionList>`, string(data))
+
+	roundTripXml(t, list)
+	roundTripJson(t, list)
+}
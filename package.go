@@ -44,9 +44,16 @@
 // Marshal to JSON for API clients, XML for CLI tools, or wrap in HTML
 // templates for browsers. Examples at ./examples/templates.
 //
-// Input validation is minimal and extensible—Validate() checks Required
-// and MinLength, matching basic browser behavior. Extend by inspecting
-// Input.Value and setting Input.Error for domain-specific rules.
+// Submitted values can be read back into a form with
+// Form.ExtractFormValue, which finds every control in Elements by
+// reflection so that new fields don't need to be wired up by hand.
+//
+// Input validation is minimal and extensible—Validate() follows HTML's
+// constraint validation for Required, Type, lengths, ranges and steps, so
+// that the server agrees with the browser. Extend by inspecting
+// Input.Value and appending to Input.Errors for domain-specific rules.
+// Form.Validate validates every control in a form at once and reports
+// whether it is valid along with each failure.
 //
 // # Pairs Well With
 //
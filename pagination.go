@@ -0,0 +1,324 @@
+package hmc
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Pagination is a group of links between the pages of a collection, each
+// with one of the relation types "first", "prev", "next" and "last", as
+// built by a [Pager].
+//
+// Label describes the current page, such as "Page 3 of 10". Page is the
+// number of the current page, counting from 1, and Pages the number of
+// pages, with Total items across them. Each is empty or 0 if it isn't
+// known, as with cursors, or doesn't apply, as Page and Pages don't with
+// offsets.
+//
+// Since its links are ordinary c:Link elements, a client that doesn't know
+// c:Pagination can still find them by their relation types.
+type Pagination struct {
+	Label string `json:"label,omitempty"`
+	Page  int    `json:"page,omitempty"`
+	Pages int    `json:"pages,omitempty"`
+	Total int    `json:"total,omitempty"`
+	Links []Link `json:"links"`
+}
+
+func (Pagination) controlName() string { return "Pagination" }
+
+// Link returns the first of [Pagination.Links] with the relation type rel,
+// such as "next".
+func (p Pagination) Link(rel string) (Link, bool) {
+	for _, l := range p.Links {
+		if l.HasRel(rel) {
+			return l, true
+		}
+	}
+	return Link{}, false
+}
+
+// UnmarshalJSON replaces p with the Pagination in data. The page numbers
+// and total are omitted when unknown, as with cursors, so decoding such a
+// Pagination clears them.
+func (p *Pagination) UnmarshalJSON(data []byte) error {
+	var j paginationJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*p = Pagination(j)
+	return nil
+}
+
+// MarshalXML marshals the Pagination as a c:Pagination element, with each
+// of its Links as a c:Link child.
+func (p Pagination) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Pagination"}}
+	if p.Label != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: p.Label})
+	}
+	if p.Page > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "page"}, Value: strconv.Itoa(p.Page)})
+	}
+	if p.Pages > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "pages"}, Value: strconv.Itoa(p.Pages)})
+	}
+	if p.Total > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "total"}, Value: strconv.Itoa(p.Total)})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, l := range p.Links {
+		if err := e.Encode(l); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:Pagination element as marshalled by
+// [Pagination.MarshalXML].
+func (p *Pagination) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = Pagination{}
+
+	for _, a := range start.Attr {
+		var err error
+		switch a.Name.Local {
+		case "label":
+			p.Label = a.Value
+		case "page":
+			p.Page, err = strconv.Atoi(a.Value)
+		case "pages":
+			p.Pages, err = strconv.Atoi(a.Value)
+		case "total":
+			p.Total, err = strconv.Atoi(a.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("c:Pagination %s attribute: %w", a.Name.Local, err)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Link") {
+			var l Link
+			if err := d.DecodeElement(&l, &child); err != nil {
+				return err
+			}
+			p.Links = append(p.Links, l)
+			return nil
+		}
+		return d.Skip()
+	})
+}
+
+// Pager builds the [Pagination] of a collection, whose pages are chosen
+// with query parameters of the request's URL: either a page number, named
+// by PageParam ("page" by default), an opaque cursor, named by CursorParam
+// ("cursor" by default), or an offset into the collection, named by
+// OffsetParam ("offset" by default), with a number of items named by
+// LimitParam ("limit" by default).
+//
+// The links keep the request's other query parameters, such as filters,
+// so that every page is of the same collection.
+type Pager struct {
+	PageParam   string
+	CursorParam string
+	OffsetParam string
+	LimitParam  string
+}
+
+func (p Pager) pageParam() string {
+	return cmp.Or(p.PageParam, "page")
+}
+
+func (p Pager) cursorParam() string {
+	return cmp.Or(p.CursorParam, "cursor")
+}
+
+func (p Pager) offsetParam() string {
+	return cmp.Or(p.OffsetParam, "offset")
+}
+
+func (p Pager) limitParam() string {
+	return cmp.Or(p.LimitParam, "limit")
+}
+
+// ParsePage reads the page number from the URL of a request. The page is 1
+// if it is missing or invalid. Its items start at (page-1)*pageSize.
+func (p Pager) ParsePage(u *url.URL) int {
+	page, err := strconv.Atoi(u.Query().Get(p.pageParam()))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// ParseCursor reads the cursor from the URL of a request. It is empty for
+// the first page.
+func (p Pager) ParseCursor(u *url.URL) string {
+	return u.Query().Get(p.cursorParam())
+}
+
+// ParseOffset reads the offset and limit from the URL of a request. The
+// offset is 0 if it is missing or invalid. The limit is defaultLimit if it
+// is missing or invalid, and no more than maxLimit. If maxLimit isn't
+// positive, the limit is no more than defaultLimit instead, so that a
+// client can't ask for every item at once.
+func (p Pager) ParseOffset(u *url.URL, defaultLimit, maxLimit int) (offset, limit int) {
+	q := u.Query()
+	offset, err := strconv.Atoi(q.Get(p.offsetParam()))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	limit, err = strconv.Atoi(q.Get(p.limitParam()))
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = defaultLimit
+	}
+	if maxLimit > 0 {
+		limit = min(limit, maxLimit)
+	}
+	return offset, limit
+}
+
+// Pages returns the Pagination of page, as read by [Pager.ParsePage], of a
+// collection of total items in pages of pageSize, with links relative to
+// u, the URL of the request.
+//
+// There is always at least one page, even if the collection is empty, and
+// the first and last links are always given. A page past the last is
+// treated as the last, so the Page of the result is the one whose items
+// should be served. The prev link is given unless page is the first, and
+// the next link unless it is the last. If pageSize isn't positive, every
+// item is on one page.
+func (p Pager) Pages(u *url.URL, page, pageSize, total int) Pagination {
+	pages := 1
+	if pageSize > 0 && total > pageSize {
+		pages = (total + pageSize - 1) / pageSize
+	}
+	page = min(max(page, 1), pages)
+
+	link := func(label, rel string, page int) Link {
+		q := u.Query()
+		if page > 1 {
+			q.Set(p.pageParam(), strconv.Itoa(page))
+		} else {
+			q.Del(p.pageParam())
+		}
+		return Link{Label: label, Href: relativeURL(u, q), Rel: rel}
+	}
+
+	pagination := Pagination{
+		Label: fmt.Sprintf("Page %d of %d", page, pages),
+		Page:  page,
+		Pages: pages,
+		Total: total,
+	}
+	pagination.Links = append(pagination.Links, link("First", "first", 1))
+	if page > 1 {
+		pagination.Links = append(pagination.Links, link("Previous", "prev", page-1))
+	}
+	if page < pages {
+		pagination.Links = append(pagination.Links, link("Next", "next", page+1))
+	}
+	pagination.Links = append(pagination.Links, link("Last", "last", pages))
+	return pagination
+}
+
+// Offsets returns the Pagination of the limit items from offset, as read
+// by [Pager.ParseOffset], of a collection of total items, with links
+// relative to u, the URL of the request. Its Label describes the items,
+// such as "Items 21 to 30 of 95".
+//
+// The first and last links are always given, and lead to the first and
+// last limit items. An offset past the last item is treated as the last
+// link's, so the offset of the items that should be served is that of the
+// result's Label. The prev link is given unless offset is 0, and the next
+// link unless the items reach the end. If limit isn't positive, every item
+// is in one page.
+func (p Pager) Offsets(u *url.URL, offset, limit, total int) Pagination {
+	if limit <= 0 {
+		limit = max(total, 1)
+	}
+	last := max(total-1, 0) / limit * limit
+	offset = max(offset, 0)
+	if offset >= total {
+		offset = last
+	}
+
+	link := func(label, rel string, offset int) Link {
+		q := u.Query()
+		if offset > 0 {
+			q.Set(p.offsetParam(), strconv.Itoa(offset))
+		} else {
+			q.Del(p.offsetParam())
+		}
+		return Link{Label: label, Href: relativeURL(u, q), Rel: rel}
+	}
+
+	// The limit is clamped to the items left before adding it to offset,
+	// as a huge limit would overflow.
+	end := offset + min(limit, total-offset)
+
+	pagination := Pagination{
+		Label: "No items",
+		Total: total,
+	}
+	if total > 0 {
+		pagination.Label = fmt.Sprintf("Items %d to %d of %d", offset+1, end, total)
+	}
+	pagination.Links = append(pagination.Links, link("First", "first", 0))
+	if offset > 0 {
+		pagination.Links = append(pagination.Links, link("Previous", "prev", max(offset-limit, 0)))
+	}
+	if end < total {
+		pagination.Links = append(pagination.Links, link("Next", "next", end))
+	}
+	pagination.Links = append(pagination.Links, link("Last", "last", last))
+	return pagination
+}
+
+// Cursors returns the Pagination of a page of a collection that is paged
+// with cursors, with links relative to u, the URL of the request. prev and
+// next are the cursors of the pages either side of the current one, and
+// either is empty if there is no such page.
+//
+// The first link is always given. Since the number of pages isn't known,
+// there is no last link, and the Pagination has no Label.
+func (p Pager) Cursors(u *url.URL, prev, next string) Pagination {
+	link := func(label, rel, cursor string) Link {
+		q := u.Query()
+		if cursor != "" {
+			q.Set(p.cursorParam(), cursor)
+		} else {
+			q.Del(p.cursorParam())
+		}
+		return Link{Label: label, Href: relativeURL(u, q), Rel: rel}
+	}
+
+	var pagination Pagination
+	pagination.Links = append(pagination.Links, link("First", "first", ""))
+	if prev != "" {
+		pagination.Links = append(pagination.Links, link("Previous", "prev", prev))
+	}
+	if next != "" {
+		pagination.Links = append(pagination.Links, link("Next", "next", next))
+	}
+	return pagination
+}
+
+// relativeURL returns the path of u, escaped as it is in u, with the query
+// q, as a reference relative to u's host.
+func relativeURL(u *url.URL, q url.Values) string {
+	return (&url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: q.Encode()}).String()
+}
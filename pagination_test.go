@@ -0,0 +1,154 @@
+package hmc_test
+
+import (
+	"math"
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func hrefs(p hmc.Pagination) map[string]string {
+	hrefs := map[string]string{}
+	for _, l := range p.Links {
+		hrefs[l.Rel] = l.Href
+	}
+	return hrefs
+}
+
+func TestPagerPages(t *testing.T) {
+	u, _ := url.Parse("https://example.com/orders?status=paid&page=3")
+	pager := hmc.Pager{}
+
+	page := pager.ParsePage(u)
+	assert.Eq(t, "page", 3, page)
+
+	p := pager.Pages(u, page, 10, 95)
+	assert.Eq(t, "label", "Page 3 of 10", p.Label)
+	assert.Eq(t, "pages", 10, p.Pages)
+	assert.Eq(t, "links", 4, len(p.Links))
+	links := hrefs(p)
+	assert.Eq(t, "first", "/orders?status=paid", links["first"])
+	assert.Eq(t, "prev", "/orders?page=2&status=paid", links["prev"])
+	assert.Eq(t, "next", "/orders?page=4&status=paid", links["next"])
+	assert.Eq(t, "last", "/orders?page=10&status=paid", links["last"])
+
+	next, ok := p.Link("next")
+	assert.True(t, "found next", ok)
+	assert.Eq(t, "next label", "Next", next.Label)
+}
+
+func TestPagerPagesEdges(t *testing.T) {
+	u, _ := url.Parse("/orders")
+	pager := hmc.Pager{PageParam: "p"}
+
+	p := pager.Pages(u, 1, 10, 0)
+	assert.Eq(t, "empty label", "Page 1 of 1", p.Label)
+	assert.Eq(t, "empty links", 2, len(p.Links))
+	assert.Eq(t, "empty first", "/orders", hrefs(p)["first"])
+	assert.Eq(t, "empty last", "/orders", hrefs(p)["last"])
+
+	p = pager.Pages(u, 10, 10, 100)
+	assert.Eq(t, "last page label", "Page 10 of 10", p.Label)
+	_, ok := p.Link("next")
+	assert.True(t, "no next on last page", !ok)
+	assert.Eq(t, "last page prev", "/orders?p=9", hrefs(p)["prev"])
+
+	p = pager.Pages(u, 12, 10, 95)
+	assert.Eq(t, "page past the last is the last", "Page 10 of 10", p.Label)
+	assert.Eq(t, "page past the last", 10, p.Page)
+	_, ok = p.Link("next")
+	assert.True(t, "no next past last page", !ok)
+	assert.Eq(t, "prev past last page", "/orders?p=9", hrefs(p)["prev"])
+
+	escaped, _ := url.Parse("/teams/a%2Fb/orders?p=2")
+	p = pager.Pages(escaped, 2, 10, 95)
+	assert.Eq(t, "escaped path is kept", "/teams/a%2Fb/orders?p=3", hrefs(p)["next"])
+
+	p = pager.Pages(u, 1, 0, 95)
+	assert.Eq(t, "no page size", "Page 1 of 1", p.Label)
+
+	assert.Eq(t, "invalid page", 1, pager.ParsePage(&url.URL{RawQuery: "p=-2"}))
+	assert.Eq(t, "missing page", 1, pager.ParsePage(&url.URL{RawQuery: "page=2"}))
+}
+
+func TestPagerOffsets(t *testing.T) {
+	pager := hmc.Pager{}
+	for _, c := range []struct {
+		name   string
+		url    string
+		total  int
+		label  string
+		hrefs  map[string]string
+		offset int
+		limit  int
+	}{
+		{"middle", "/orders?status=paid&offset=20&limit=10", 95, "Items 21 to 30 of 95", map[string]string{
+			"first": "/orders?limit=10&status=paid",
+			"prev":  "/orders?limit=10&offset=10&status=paid",
+			"next":  "/orders?limit=10&offset=30&status=paid",
+			"last":  "/orders?limit=10&offset=90&status=paid",
+		}, 20, 10},
+		{"unaligned", "/orders?offset=5", 25, "Items 6 to 15 of 25", map[string]string{
+			"first": "/orders",
+			"prev":  "/orders",
+			"next":  "/orders?offset=15",
+			"last":  "/orders?offset=20",
+		}, 5, 10},
+		{"past the end", "/orders?offset=500&limit=1000", 25, "Items 1 to 25 of 25", map[string]string{
+			"first": "/orders?limit=1000",
+			"last":  "/orders?limit=1000",
+		}, 500, 50},
+		{"empty", "/orders?offset=-3&limit=x", 0, "No items", map[string]string{
+			"first": "/orders?limit=x",
+			"last":  "/orders?limit=x",
+		}, 0, 10},
+	} {
+		u, _ := url.Parse(c.url)
+		offset, limit := pager.ParseOffset(u, 10, 50)
+		assert.Eq(t, c.name+": offset", c.offset, offset)
+		assert.Eq(t, c.name+": limit", c.limit, limit)
+
+		p := pager.Offsets(u, offset, limit, c.total)
+		assert.Eq(t, c.name+": label", c.label, p.Label)
+		assert.Eq(t, c.name+": total", c.total, p.Total)
+		assert.Eq(t, c.name+": links", len(c.hrefs), len(p.Links))
+		for rel, href := range hrefs(p) {
+			assert.Eq(t, c.name+": "+rel, c.hrefs[rel], href)
+		}
+	}
+}
+
+func TestPagerOffsetsHugeLimit(t *testing.T) {
+	pager := hmc.Pager{}
+	u, _ := url.Parse("/orders?offset=20&limit=9223372036854775807")
+
+	offset, limit := pager.ParseOffset(u, 10, 0)
+	assert.Eq(t, "offset", 20, offset)
+	assert.Eq(t, "limit is capped to the default", 10, limit)
+
+	p := pager.Offsets(u, offset, math.MaxInt, 95)
+	assert.Eq(t, "label", "Items 21 to 95 of 95", p.Label)
+	links := hrefs(p)
+	assert.Eq(t, "no next", "", links["next"])
+	assert.Eq(t, "prev", "/orders?limit=9223372036854775807", links["prev"])
+}
+
+func TestPagerCursors(t *testing.T) {
+	u, _ := url.Parse("/events?type=login&cursor=b")
+	pager := hmc.Pager{}
+
+	assert.Eq(t, "cursor", "b", pager.ParseCursor(u))
+
+	p := pager.Cursors(u, "a", "c")
+	assert.Eq(t, "label", "", p.Label)
+	assert.Eq(t, "links", 3, len(p.Links))
+	links := hrefs(p)
+	assert.Eq(t, "first", "/events?type=login", links["first"])
+	assert.Eq(t, "prev", "/events?cursor=a&type=login", links["prev"])
+	assert.Eq(t, "next", "/events?cursor=c&type=login", links["next"])
+
+	p = pager.Cursors(u, "", "")
+	assert.Eq(t, "only first", 1, len(p.Links))
+}
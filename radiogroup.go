@@ -0,0 +1,188 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+)
+
+// RadioGroup is analogous to a set of HTML's <input type="radio"> that
+// share a name. It represents a choice of exactly one of a few Options,
+// all of which are shown at once.
+//
+// Unlike [Select], a RadioGroup is always exhaustive: only the values in
+// Options are accepted. The chosen option is the one that is
+// [Option.Selected].
+type RadioGroup struct {
+	Label    string   `json:"label"`
+	Name     string   `json:"name"`
+	Required bool     `json:"required,omitempty"`
+	Options  []Option `json:"options"`
+	Errors   []string `json:"errors,omitempty"`
+
+	// unknown holds a value given to SetValue that isn't among the Options,
+	// so that Validate can report it.
+	unknown string
+}
+
+func (RadioGroup) controlName() string { return "RadioGroup" }
+
+func (r *RadioGroup) scope(from, to string) { r.Name = rescopeName(r.Name, from, to) }
+
+func (r RadioGroup) addFormValues(values url.Values) {
+	if v := r.Value(); v != "" {
+		values.Add(r.Name, v)
+	}
+}
+
+// UnmarshalJSON replaces r with the RadioGroup in data, dropping any
+// unknown value given to [RadioGroup.SetValue], which isn't marshalled.
+func (r *RadioGroup) UnmarshalJSON(data []byte) error {
+	var j radioGroupJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*r = RadioGroup(j)
+	return nil
+}
+
+// Value returns the value of the chosen option, or "" if none is chosen.
+func (r RadioGroup) Value() string {
+	for _, o := range r.Options {
+		if o.Selected {
+			return o.Value
+		}
+	}
+	return ""
+}
+
+// SetValue chooses the option with the given value, and unchooses all
+// others. If value isn't among the Options, none are chosen, and the value
+// is reported by [RadioGroup.Validate].
+func (r *RadioGroup) SetValue(value string) {
+	r.unknown = value
+	for i := range r.Options {
+		r.Options[i].Selected = r.Options[i].Value == value
+		if r.Options[i].Selected {
+			r.unknown = ""
+		}
+	}
+}
+
+// ExtractFormValue chooses the option with the first value of Name in
+// form, as [RadioGroup.SetValue] does, and deletes that value from form.
+//
+// Since a browser doesn't submit a RadioGroup with no option chosen, the
+// chosen option is left as it is if form has no value for Name.
+func (r *RadioGroup) ExtractFormValue(form url.Values) {
+	formValue, ok := form[r.Name]
+	if !ok {
+		return
+	}
+	r.SetValue(formValue[0])
+	if len(formValue[1:]) > 0 {
+		form[r.Name] = formValue[1:]
+	} else {
+		delete(form, r.Name)
+	}
+}
+
+// Validate checks the chosen option, appending a message for each problem
+// to [RadioGroup.Errors]:
+//
+//   - If [RadioGroup.Required] is set, an option must be chosen.
+//   - A [Option.Disabled] option must not be chosen.
+//   - A value given to [RadioGroup.SetValue] or
+//     [RadioGroup.ExtractFormValue] must be among the Options.
+//
+// The messages of an earlier call to Validate are replaced, and other
+// errors that are already present are kept.
+func (r *RadioGroup) Validate() {
+	r.Errors = radioGroupMessages.clear(r.Errors)
+
+	if r.unknown != "" {
+		r.Errors = append(r.Errors, fmt.Sprintf(msgUnknownOption, r.unknown, r.Name))
+	} else if r.Required && r.Value() == "" {
+		r.Errors = append(r.Errors, fmt.Sprintf(msgRequired, r.Name))
+	}
+
+	for _, o := range r.Options {
+		if o.Selected && o.Disabled {
+			r.Errors = append(r.Errors, fmt.Sprintf(msgUnavailable, o.Value, r.Name))
+		}
+	}
+}
+
+var radioGroupMessages = newMessageFormats(msgUnknownOption, msgRequired, msgUnavailable)
+
+// FirstError returns the first of [RadioGroup.Errors], or "" if there are
+// none.
+func (r RadioGroup) FirstError() string {
+	return firstError(r.Errors)
+}
+
+// FieldErrors reports each of [RadioGroup.Errors].
+func (r RadioGroup) FieldErrors() []FieldError {
+	return fieldErrors(r.Name, r.Errors)
+}
+
+// MarshalXML marshals the group as a c:RadioGroup element, with each of
+// Options as a c:Option element.
+func (r RadioGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:RadioGroup"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: r.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: r.Name})
+	if r.Required {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "required"}, Value: "true"})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, o := range r.Options {
+		if err := e.Encode(o); err != nil {
+			return err
+		}
+	}
+
+	if err := encodeErrors(e, r.Errors); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:RadioGroup element as marshalled by
+// [RadioGroup.MarshalXML].
+func (r *RadioGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*r = RadioGroup{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			r.Label = a.Value
+		case "name":
+			r.Name = a.Value
+		case "required":
+			r.Required = parseBoolAttr(a.Value)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch {
+		case isControlElement(child.Name, "Option"):
+			var o Option
+			if err := d.DecodeElement(&o, &child); err != nil {
+				return err
+			}
+			r.Options = append(r.Options, o)
+			return nil
+		case isControlElement(child.Name, "Error"):
+			return decodeError(d, child, &r.Errors)
+		default:
+			return d.Skip()
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func newSize() hmc.RadioGroup {
+	return hmc.RadioGroup{Label: "Size", Name: "size", Required: true, Options: []hmc.Option{
+		{Label: "Small", Value: "sm"},
+		{Label: "Medium", Value: "md", Selected: true},
+		{Label: "Large", Value: "lg", Disabled: true},
+	}}
+}
+
+func TestRadioGroupValidate(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		form     url.Values
+		selected bool
+		value    string
+		expected []string
+	}{
+		{"missing is left as it is", url.Values{}, true, "md", nil},
+		{"chosen", url.Values{"size": {"sm"}}, true, "sm", nil},
+		{"unknown", url.Values{"size": {"xl"}}, true, "", []string{`"xl" is not one of the options for "size"`}},
+		{"disabled", url.Values{"size": {"lg"}}, true, "lg", []string{`"lg" is not available for "size"`}},
+		{"empty", url.Values{"size": {""}}, true, "", []string{`"size" is required`}},
+		{"required", url.Values{}, false, "", []string{`"size" is required`}},
+	} {
+		r := newSize()
+		r.Options[1].Selected = c.selected
+		r.ExtractFormValue(c.form)
+		r.Validate()
+		assert.Eq(t, c.name+": value", c.value, r.Value())
+		assert.SlicesEq(t, c.name+": errors", c.expected, r.Errors)
+
+		r.Validate()
+		assert.SlicesEq(t, c.name+": errors when validated again", c.expected, r.Errors)
+	}
+}
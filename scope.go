@@ -0,0 +1,61 @@
+package hmc
+
+import (
+	"cmp"
+	"reflect"
+	"strings"
+)
+
+// scoper is implemented by controls whose names can be moved under a
+// prefix, as the controls of a [List] item are.
+type scoper interface {
+	// scope moves the control's names from under the prefix from to under
+	// the prefix to. See rescopeName.
+	scope(from, to string)
+}
+
+// scopeNames calls scope on every [scoper] in v.
+func scopeNames(v reflect.Value, from, to string) {
+	walk(v, func(v reflect.Value) bool {
+		if s, ok := asAddressable(v).(scoper); ok {
+			s.scope(from, to)
+			return true
+		}
+		return false
+	})
+}
+
+// rescopeName moves name from under the prefix from to under the prefix
+// to, in bracket notation. If from is empty, name is taken to be unscoped,
+// so that e.g. "tags[]" scoped to "items[0]" becomes "items[0][tags][]".
+// An empty name, as of a catch-all [Map], becomes to itself.
+//
+// If to is empty, name is unscoped, which is the inverse of scoping it
+// to from. Names that aren't under from are left as they are.
+func rescopeName(name, from, to string) string {
+	if from != "" && to == "" {
+		if name == from {
+			return ""
+		}
+		path, ok := Map{Name: from}.Path(name)
+		if !ok {
+			return name
+		}
+		return Map{}.NamedKey(path...)
+	}
+	if from != "" {
+		rest, ok := strings.CutPrefix(name, from)
+		if !ok || rest != "" && rest[0] != '[' {
+			return name
+		}
+		return to + rest
+	}
+	if name == "" || to == "" {
+		return cmp.Or(name, to)
+	}
+	first, rest, nested := strings.Cut(name, "[")
+	if nested {
+		return to + "[" + first + "][" + rest
+	}
+	return to + "[" + first + "]"
+}
@@ -2,7 +2,9 @@ package hmc
 
 import (
 	"cmp"
+	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"iter"
 	"net/url"
 )
@@ -29,28 +31,191 @@ func (o Option) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return e.EncodeElement(label, start)
 }
 
-type Select struct {
-	Multiple bool     `json:"multiple,omitempty"`
+// UnmarshalXML decodes a c:Option element as marshalled by
+// [Option.MarshalXML].
+func (o *Option) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*o = Option{}
+
+	hasValue := false
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "selected":
+			o.Selected = parseBoolAttr(a.Value)
+		case "disabled":
+			o.Disabled = parseBoolAttr(a.Value)
+		case "value":
+			o.Value = a.Value
+			hasValue = true
+		}
+	}
+
+	var content string
+	if err := d.DecodeElement(&content, &start); err != nil {
+		return err
+	}
+
+	if hasValue {
+		o.Label = content
+	} else {
+		o.Value = content
+	}
+
+	return nil
+}
+
+// UnmarshalJSON replaces o with the Option in data, so decoding an option
+// that data doesn't mark as selected into a selected Option deselects it.
+func (o *Option) UnmarshalJSON(data []byte) error {
+	var j optionJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*o = Option(j)
+	return nil
+}
+
+// OptGroup is analogous to HTML's <optgroup>. It gives a group of options
+// within a [Select] a label.
+//
+// If Disabled is set, none of its options may be selected.
+type OptGroup struct {
 	Label    string   `json:"label"`
-	Name     string   `json:"name"`
-	Required bool     `json:"required,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
 	Options  []Option `json:"options"`
-	Error    string   `json:"error,omitempty"`
 }
 
+// UnmarshalJSON replaces g with the OptGroup in data. Disabled is omitted
+// from JSON when unset, so decoding into a disabled group enables it again.
+func (g *OptGroup) UnmarshalJSON(data []byte) error {
+	var j optGroupJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*g = OptGroup(j)
+	return nil
+}
+
+func (g OptGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:OptGroup"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: g.Label})
+	if g.Disabled {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "disabled"}})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, o := range g.Options {
+		if err := e.Encode(o); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:OptGroup element as marshalled by
+// [OptGroup.MarshalXML].
+func (g *OptGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*g = OptGroup{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			g.Label = a.Value
+		case "disabled":
+			g.Disabled = parseBoolAttr(a.Value)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isControlElement(child.Name, "Option") {
+			var o Option
+			if err := d.DecodeElement(&o, &child); err != nil {
+				return err
+			}
+			g.Options = append(g.Options, o)
+			return nil
+		}
+		return d.Skip()
+	})
+}
+
+// Select is analogous to HTML's <select>.
+//
+// Unlike HTML, Options is not exhaustive by default: the client may submit
+// values that aren't listed. If Exhaustive is set, only the values in
+// Options are accepted.
+//
+// Options may also be arranged in labelled Groups, which follow the
+// ungrouped Options. Methods that deal with options look in both.
+//
+// As in XML, the Groups are marshalled to JSON in "options", after the
+// ungrouped Options. A group has a nested array of its own "options",
+// which an option never has, and that is how the two are told apart:
+//
+//	"options": [
+//	  {"label": "Choose a region", "value": "", "disabled": true},
+//	  {"label": "Oceania", "options": [
+//	    {"label": "New Zealand", "value": "nz"},
+//	    {"label": "Australia", "value": "au"}
+//	  ]}
+//	]
+//
+// A Select with too many options to list may instead, or as well, give a
+// Source from which clients fetch them as needed. Only the options listed
+// in the Select itself are known to its methods, so values of a Select
+// with a Source are treated as non-exhaustive, and should be checked
+// against the source by the server.
+type Select struct {
+	Multiple   bool          `json:"multiple,omitempty"`
+	Exhaustive bool          `json:"exhaustive,omitempty"`
+	Label      string        `json:"label"`
+	Name       string        `json:"name"`
+	Required   bool          `json:"required,omitempty"`
+	Options    []Option      `json:"options"`
+	Groups     []OptGroup    `json:"-"`
+	Source     *OptionSource `json:"source,omitempty"`
+	Errors     []string      `json:"errors,omitempty"`
+
+	// unknown holds values given to SetValues that aren't among the Options
+	// of an Exhaustive Select, so that Validate can report them.
+	unknown []string
+}
+
+func (Select) controlName() string { return "Select" }
+
+func (s *Select) scope(from, to string) { s.Name = rescopeName(s.Name, from, to) }
+
+func (s Select) addFormValues(values url.Values) {
+	for v := range s.Values() {
+		values.Add(s.Name, v)
+	}
+}
+
+// SetValues selects the options with the given values, and deselects all
+// others.
+//
+// Values that aren't among the Options are added as new selected options,
+// unless the Select is Exhaustive and has no Source, in which case they are
+// left out and reported by [Select.Validate].
 func (s *Select) SetValues(values ...string) {
-	for i := range s.Options {
-		s.Options[i].Selected = false
+	for o := range s.options() {
+		o.Selected = false
 	}
+	s.unknown = nil
 	for _, v := range values {
 		found := false
-		for i, o := range s.Options {
+		for o := range s.options() {
 			if o.Value == v {
-				s.Options[i].Selected = true
+				o.Selected = true
 				found = true
 			}
 		}
-		if !found {
+		if !found && s.Exhaustive && s.Source == nil {
+			s.unknown = append(s.unknown, v)
+		} else if !found {
 			s.Options = append([]Option{{
 				Value:    v,
 				Selected: true,
@@ -59,9 +224,51 @@ func (s *Select) SetValues(values ...string) {
 	}
 }
 
+// options yields a pointer to each of the Options, followed by each of the
+// options in Groups, along with whether it is in a disabled group.
+func (s *Select) options() iter.Seq2[*Option, bool] {
+	return func(yield func(*Option, bool) bool) {
+		for i := range s.Options {
+			if !yield(&s.Options[i], false) {
+				return
+			}
+		}
+		for _, g := range s.Groups {
+			for i := range g.Options {
+				if !yield(&g.Options[i], g.Disabled) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllOptions yields each of the Options, followed by each of the options in
+// Groups.
+func (s Select) AllOptions() iter.Seq[Option] {
+	return func(yield func(Option) bool) {
+		for o := range s.options() {
+			if !yield(*o) {
+				return
+			}
+		}
+	}
+}
+
+// HasOption reports whether value is among the Options, or the options of
+// any of the Groups.
+func (s Select) HasOption(value string) bool {
+	for o := range s.options() {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
 func (s Select) Values() iter.Seq[string] {
 	return iter.Seq[string](func(yield func(string) bool) {
-		for _, o := range s.Options {
+		for o := range s.options() {
 			if o.Selected {
 				if !yield(o.Value) {
 					return
@@ -96,12 +303,113 @@ func (s *Select) ExtractFormValue(form url.Values) {
 	}
 }
 
+// Validate checks the selected options, appending a message for each
+// problem to [Select.Errors]:
+//
+//   - If [Select.Required] is set, an option with a non-empty value must be
+//     selected.
+//   - A [Option.Disabled] option, or an option of a disabled [OptGroup],
+//     must not be selected, unless its value is empty, as it may be a
+//     placeholder such as "Choose one...".
+//   - If [Select.Exhaustive] is set, and there is no [Select.Source],
+//     values given to [Select.SetValues] or [Select.ExtractFormValue] must
+//     be among the Options.
+//
+// The messages of an earlier call to Validate are replaced, and other
+// errors that are already present are kept.
+func (s *Select) Validate() {
+	s.Errors = selectMessages.clear(s.Errors)
+
+	if s.Required && s.Value() == "" && len(s.unknown) == 0 {
+		s.Errors = append(s.Errors, fmt.Sprintf(msgRequired, s.Name))
+	}
+
+	for o, groupDisabled := range s.options() {
+		if o.Selected && (o.Disabled || groupDisabled) && o.Value != "" {
+			s.Errors = append(s.Errors, fmt.Sprintf(msgUnavailable, o.Value, s.Name))
+		}
+	}
+
+	for _, v := range s.unknown {
+		s.Errors = append(s.Errors, fmt.Sprintf(msgUnknownOption, v, s.Name))
+	}
+}
+
+const (
+	msgUnavailable   = "%#v is not available for %#v"
+	msgUnknownOption = "%#v is not one of the options for %#v"
+)
+
+var selectMessages = newMessageFormats(msgRequired, msgUnavailable, msgUnknownOption)
+
+// FirstError returns the first of [Select.Errors], or "" if there are none.
+func (s Select) FirstError() string {
+	return firstError(s.Errors)
+}
+
+// FieldErrors reports each of [Select.Errors].
+func (s Select) FieldErrors() []FieldError {
+	return fieldErrors(s.Name, s.Errors)
+}
+
+// MarshalJSON marshals the Select with its Groups listed in "options",
+// after the ungrouped Options.
+func (s Select) MarshalJSON() ([]byte, error) {
+	j := selectJson{
+		Multiple:   s.Multiple,
+		Exhaustive: s.Exhaustive,
+		Label:      s.Label,
+		Name:       s.Name,
+		Required:   s.Required,
+		Options:    make([]selectEntryJson, 0, len(s.Options)+len(s.Groups)),
+		Source:     s.Source,
+		Errors:     s.Errors,
+	}
+	for _, o := range s.Options {
+		j.Options = append(j.Options, selectEntryJson{option: &o})
+	}
+	for _, g := range s.Groups {
+		j.Options = append(j.Options, selectEntryJson{group: &g})
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a Select as marshalled by [Select.MarshalJSON]. An
+// entry of "options" with a nested array of "options" is decoded as one of
+// Groups.
+func (s *Select) UnmarshalJSON(data []byte) error {
+	var j selectJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = Select{
+		Multiple:   j.Multiple,
+		Exhaustive: j.Exhaustive,
+		Label:      j.Label,
+		Name:       j.Name,
+		Required:   j.Required,
+		Source:     j.Source,
+		Errors:     j.Errors,
+	}
+	for _, e := range j.Options {
+		if e.group != nil {
+			s.Groups = append(s.Groups, *e.group)
+		} else {
+			s.Options = append(s.Options, *e.option)
+		}
+	}
+	return nil
+}
+
 func (i Select) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "c:Select"
 
 	if i.Multiple {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "multiple"}})
 	}
+	if i.Exhaustive {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "exhaustive"}})
+	}
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: i.Label})
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: i.Name})
 	if i.Required {
@@ -112,11 +420,74 @@ func (i Select) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return nil
 	}
 
+	if i.Source != nil {
+		if err := e.Encode(*i.Source); err != nil {
+			return err
+		}
+	}
+
 	for _, o := range i.Options {
 		if err := e.EncodeElement(o, start); err != nil {
 			return err
 		}
 	}
 
+	for _, g := range i.Groups {
+		if err := e.Encode(g); err != nil {
+			return err
+		}
+	}
+
+	if err := encodeErrors(e, i.Errors); err != nil {
+		return err
+	}
+
 	return e.EncodeToken(start.End())
 }
+
+// UnmarshalXML decodes a c:Select element as marshalled by
+// [Select.MarshalXML].
+func (s *Select) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*s = Select{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "multiple":
+			s.Multiple = parseBoolAttr(a.Value)
+		case "exhaustive":
+			s.Exhaustive = parseBoolAttr(a.Value)
+		case "label":
+			s.Label = a.Value
+		case "name":
+			s.Name = a.Value
+		case "required":
+			s.Required = parseBoolAttr(a.Value)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch {
+		case isControlElement(child.Name, "Option"):
+			var o Option
+			if err := d.DecodeElement(&o, &child); err != nil {
+				return err
+			}
+			s.Options = append(s.Options, o)
+			return nil
+		case isControlElement(child.Name, "OptGroup"):
+			var g OptGroup
+			if err := d.DecodeElement(&g, &child); err != nil {
+				return err
+			}
+			s.Groups = append(s.Groups, g)
+			return nil
+		case isControlElement(child.Name, "OptionSource"):
+			s.Source = &OptionSource{}
+			return d.DecodeElement(s.Source, &child)
+		case isControlElement(child.Name, "Error"):
+			return decodeError(d, child, &s.Errors)
+		default:
+			return d.Skip()
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package hmc_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func sizes() hmc.Select {
+	return hmc.Select{
+		Label:    "Mug size",
+		Name:     "mugs",
+		Required: true,
+		Options: []hmc.Option{
+			{Label: "Choose a size", Disabled: true},
+			{Label: "Large", Value: "lg"},
+			{Label: "Medium", Value: "md"},
+			{Label: "Wumbo", Value: "wb", Disabled: true},
+		},
+	}
+}
+
+func TestSelectValidate(t *testing.T) {
+	for _, c := range []struct {
+		context    string
+		exhaustive bool
+		multiple   bool
+		form       url.Values
+		expected   []string
+	}{
+		{"valid", false, false, url.Values{"mugs": {"lg"}}, nil},
+		{"missing", false, false, url.Values{}, []string{`"mugs" is required`}},
+		{"placeholder", false, false, url.Values{"mugs": {""}}, []string{`"mugs" is required`}},
+		{"disabled", false, false, url.Values{"mugs": {"wb"}}, []string{`"wb" is not available for "mugs"`}},
+		{"unknown, non-exhaustive", false, false, url.Values{"mugs": {"xl"}}, nil},
+		{"unknown, exhaustive", true, false, url.Values{"mugs": {"xl"}}, []string{`"xl" is not one of the options for "mugs"`}},
+		{"some unknown, exhaustive", true, true, url.Values{"mugs": {"lg", "xl", "wb"}}, []string{
+			`"wb" is not available for "mugs"`,
+			`"xl" is not one of the options for "mugs"`,
+		}},
+	} {
+		s := sizes()
+		s.Exhaustive = c.exhaustive
+		s.Multiple = c.multiple
+		s.ExtractFormValue(c.form)
+		s.Validate()
+		assert.SlicesEq(t, c.context, c.expected, s.Errors)
+	}
+}
+
+func TestSelectValidateAgain(t *testing.T) {
+	s := sizes()
+	s.Errors = []string{"out of stock"}
+	s.Validate()
+	s.Validate()
+
+	assert.SlicesEq(t, "not repeated", []string{"out of stock", `"mugs" is required`}, s.Errors)
+
+	s.SetValues("lg")
+	s.Validate()
+
+	assert.SlicesEq(t, "replaced", []string{"out of stock"}, s.Errors)
+}
+
+func TestSelectExhaustiveLeavesOptionsAlone(t *testing.T) {
+	s := sizes()
+	s.Exhaustive = true
+	s.SetValues("xl")
+
+	assert.Eq(t, "no option is added", 4, len(s.Options))
+	assert.Eq(t, "nothing is selected", "", s.Value())
+	assert.True(t, "has lg", s.HasOption("lg"))
+	assert.True(t, "doesn't have xl", !s.HasOption("xl"))
+
+	s.SetValues("md")
+	s.Validate()
+	assert.Eq(t, "unknown values are forgotten", 0, len(s.Errors))
+}
+
+func TestSelectOptGroups(t *testing.T) {
+	s := hmc.Select{
+		Name:     "region",
+		Multiple: true,
+		Options:  []hmc.Option{{Value: "world", Selected: true}},
+		Groups: []hmc.OptGroup{
+			{Label: "Oceania", Options: []hmc.Option{{Value: "nz"}, {Value: "au"}}},
+			{Label: "Antarctica", Disabled: true, Options: []hmc.Option{{Value: "aq"}}},
+		},
+	}
+
+	s.SetValues("au", "aq")
+
+	assert.SlicesEq(t, "values across groups", []string{"au", "aq"}, slices.Collect(s.Values()))
+	assert.Eq(t, "no options are added", 1, len(s.Options))
+	assert.True(t, "has grouped option", s.HasOption("nz"))
+	assert.Eq(t, "all options", 4, len(slices.Collect(s.AllOptions())))
+
+	s.Validate()
+	assert.SlicesEq(t, "disabled group", []string{`"aq" is not available for "region"`}, s.Errors)
+}
+
+func TestSelectJsonEmptyOptGroup(t *testing.T) {
+	data, err := json.Marshal(hmc.Select{Name: "region", Groups: []hmc.OptGroup{{Label: "Europe"}}})
+	assert.FatalErr(t, "marshalling", err)
+
+	var s hmc.Select
+	err = json.Unmarshal(data, &s)
+	assert.FatalErr(t, "unmarshalling", err)
+
+	assert.Eq(t, "no options", 0, len(s.Options))
+	assert.Eq(t, "one group", 1, len(s.Groups))
+	assert.Eq(t, "group label", "Europe", s.Groups[0].Label)
+}
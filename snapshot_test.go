@@ -18,6 +18,20 @@ func TestMain(m *testing.M) {
 	m.Run()
 }
 
+// parseTemplates parses a fresh set of the example templates, followed by
+// defs, which defines the blocks that the examples leave to their callers,
+// such as "form_elements". Each test that defines blocks parses its own set,
+// since a set can't be cloned or redefined once it has been executed.
+func parseTemplates(t *testing.T, defs string) *template.Template {
+	t.Helper()
+
+	ptm, err := template.New("").ParseGlob("./examples/templates/*.gotmpl")
+	assert.FatalErr(t, "parsing templates", err)
+	ptm, err = ptm.Parse(defs)
+	assert.FatalErr(t, "parsing definitions", err)
+	return ptm
+}
+
 type myPage struct {
 	hmc.Namespace
 	Title string
@@ -33,26 +47,13 @@ type login struct {
 	Login           hmc.Link
 }
 
-func (l *login) ExtractValues(form url.Values) {
-	l.Username.ExtractFormValue(form)
-	l.Password.ExtractFormValue(form)
-	l.ConfirmPassword.ExtractFormValue(form)
-	l.FavouriteFood.ExtractFormValue(form)
-	l.Misc.ExtractFormValue(form)
-}
-
-func (l *login) Validate() {
-	l.Username.Validate()
-	l.Password.Validate()
-	l.ConfirmPassword.Validate()
-}
-
 func TestSnapshotForm(t *testing.T) {
 	page := myPage{
 		Namespace: hmc.SetNamespace(),
 		Title:     "Login to my thing",
 		Form: hmc.Form[login]{
 			Method: "POST",
+			Action: "/login",
 			Elements: login{
 				Username: hmc.Input{
 					Label:    "Username",
@@ -103,12 +104,178 @@ func TestSnapshotForm(t *testing.T) {
 		"favFood":          {"bugs"},
 		"misc[iq]":         {"80"},
 	}
-	page.Form.Elements.ExtractValues(form)
-	page.Form.Elements.Validate()
+	page.Form.ExtractFormValue(form)
+	ok, failures := page.Form.Validate()
 
 	assert.SnapshotXml(t, page)
 	assert.SnapshotJson(t, page)
 	assert.Eq(t, "only unmatched entries remain", 2, len(form))
+	assert.Eq(t, "form is invalid", false, ok)
+	assert.SlicesEq(t, "failures", []hmc.FieldError{
+		{Name: "confirmPassword", Message: `"confirmPassword" is required`},
+	}, failures)
+}
+
+type search struct {
+	Query hmc.Input
+}
+
+func TestSnapshotFormHtml(t *testing.T) {
+	f := hmc.Form[search]{
+		Action:  "/search",
+		Enctype: hmc.EnctypeURLEncoded,
+		Elements: search{
+			Query: hmc.Input{Label: "Query", Name: "q"},
+		},
+	}
+
+	ftm := parseTemplates(t, `{{define "form_elements"}}
+{{template "input" .Query}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ftm.ExecuteTemplate(buf, "form", f)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+}
+
+func TestSnapshotSubmit(t *testing.T) {
+	f := newArticle()
+
+	ftm := parseTemplates(t, `{{define "form_elements"}}
+{{template "input" .Title}}
+{{template "submit" .Publish}}
+{{template "submit" .Draft}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ftm.ExecuteTemplate(buf, "form", f)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+	roundTripJson(t, f)
+	roundTripXml(t, f)
+}
+
+type preferences struct {
+	Subscribe hmc.Checkbox
+	Terms     hmc.Checkbox
+	Size      hmc.RadioGroup
+}
+
+func TestSnapshotCheckboxRadio(t *testing.T) {
+	f := hmc.Form[preferences]{
+		Method: "POST",
+		Elements: preferences{
+			Subscribe: hmc.Checkbox{Label: "Subscribe to the newsletter", Name: "subscribe", Checked: true},
+			Terms:     hmc.Checkbox{Label: "I agree to the terms", Name: "terms", Value: "agreed", Required: true},
+			Size:      newSize(),
+		},
+	}
+	f.Validate()
+
+	ftm := parseTemplates(t, `{{define "form_elements"}}
+{{template "checkbox" .Subscribe}}
+{{template "checkbox" .Terms}}
+{{template "radiogroup" .Size}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ftm.ExecuteTemplate(buf, "form", f)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+	roundTripJson(t, f)
+	roundTripXml(t, f)
+}
+
+type profile struct {
+	Bio hmc.Textarea
+}
+
+func TestSnapshotTextarea(t *testing.T) {
+	f := hmc.Form[profile]{
+		Method: "POST",
+		Elements: profile{
+			Bio: hmc.Textarea{Label: "Bio", Name: "bio", Value: "Hello,\nI like <b>bold</b> text.", MaxLength: 20, Rows: 5, Cols: 40, Wrap: "hard"},
+		},
+	}
+	f.Validate()
+
+	ftm := parseTemplates(t, `{{define "form_elements"}}
+{{template "textarea" .Bio}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ftm.ExecuteTemplate(buf, "form", f)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+	roundTripJson(t, f)
+	roundTripXml(t, f)
+}
+
+func TestSnapshotCSRF(t *testing.T) {
+	f := newComment()
+	err := csrf.Issue("session-1", &f)
+	assert.FatalErr(t, "issuing", err)
+	f.ExtractFormValue(url.Values{"csrf_token": {"stale"}, "body": {"Nice post!"}})
+	f.Validate()
+
+	ftm := parseTemplates(t, `{{define "form_elements"}}
+{{template "hidden" .CSRF}}
+{{template "hidden" .Post}}
+{{template "textarea" .Body}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err = ftm.ExecuteTemplate(buf, "form", f)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+}
+
+func TestSnapshotFile(t *testing.T) {
+	f := newUpload()
+	f.Validate()
+
+	ftm := parseTemplates(t, `{{define "form_elements"}}
+{{template "input" .Title}}
+{{template "file" .Attachments}}
+{{template "file" .Avatar.Scoped.Image}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ftm.ExecuteTemplate(buf, "form", f)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+	roundTripJson(t, f)
+	roundTripXml(t, f)
+}
+
+func TestFormResolveAction(t *testing.T) {
+	base, err := url.Parse("https://example.com/accounts/login?next=%2F")
+	assert.FatalErr(t, "parsing base", err)
+
+	for _, c := range []struct{ action, expected string }{
+		{"", "https://example.com/accounts/login?next=%2F"},
+		{"session", "https://example.com/accounts/session"},
+		{"/login", "https://example.com/login"},
+		{"?retry=1", "https://example.com/accounts/login?retry=1"},
+		{"https://auth.example.com/", "https://auth.example.com/"},
+	} {
+		actual, err := hmc.Form[search]{Action: c.action}.ResolveAction(base)
+		assert.FatalErr(t, "resolving action", err)
+		assert.Eq(t, c.action, c.expected, actual.String())
+	}
 }
 
 func TestSnapshotLink(t *testing.T) {
@@ -119,6 +286,44 @@ func TestSnapshotLink(t *testing.T) {
 
 	assert.SnapshotXml(t, link)
 	assert.SnapshotJson(t, link)
+	roundTripJson(t, link)
+	roundTripXml(t, link)
+}
+
+func TestSnapshotLinkRel(t *testing.T) {
+	link := hmc.Link{
+		Label:    "Export",
+		Href:     "/orders.csv",
+		Rel:      "alternate export",
+		Type:     "text/csv",
+		Hreflang: "en",
+		Title:    "All orders as CSV",
+		Method:   "GET",
+	}
+
+	assert.SnapshotXml(t, link)
+	assert.SnapshotJson(t, link)
+	roundTripJson(t, link)
+	roundTripXml(t, link)
+}
+
+func TestSnapshotLinkTemplate(t *testing.T) {
+	link := newOrderSearch()
+
+	assert.SnapshotXml(t, link)
+	assert.SnapshotJson(t, link)
+	roundTripJson(t, link)
+	roundTripXml(t, link)
+}
+
+func TestSnapshotPagination(t *testing.T) {
+	u, _ := url.Parse("/orders?status=paid&page=3")
+	pagination := hmc.Pager{}.Pages(u, 3, 10, 95)
+
+	assert.SnapshotXml(t, pagination)
+	assert.SnapshotJson(t, pagination)
+	roundTripJson(t, pagination)
+	roundTripXml(t, pagination)
 }
 
 func TestSnapshotInput(t *testing.T) {
@@ -129,7 +334,9 @@ func TestSnapshotInput(t *testing.T) {
 		Required:  true,
 		Value:     "Hey...",
 		MinLength: 3,
-		Error:     "This is a bad message",
+		Pattern:   `\S.*`,
+		Title:     "must not start with a space",
+		Errors:    []string{"This is a bad message", "This is also a bad message"},
 	}
 
 	buf := bytes.NewBuffer([]byte{})
@@ -139,6 +346,8 @@ func TestSnapshotInput(t *testing.T) {
 	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
 	assert.SnapshotXml(t, input)
 	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
 }
 
 func TestSnapshotSelect(t *testing.T) {
@@ -165,6 +374,8 @@ func TestSnapshotSelect(t *testing.T) {
 	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
 	assert.SnapshotXml(t, input)
 	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
 	assert.Eq(t, "only unmatched entries remain", 1, len(form))
 }
 
@@ -190,6 +401,110 @@ func TestSnapshotMultiSelect(t *testing.T) {
 	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
 	assert.SnapshotXml(t, input)
 	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
+}
+
+func TestSnapshotOptGroups(t *testing.T) {
+	input := hmc.Select{
+		Label:    "Region",
+		Name:     "region",
+		Required: true,
+		Options: []hmc.Option{
+			{Label: "Choose a region", Disabled: true},
+		},
+		Groups: []hmc.OptGroup{
+			{Label: "Oceania", Options: []hmc.Option{
+				{Label: "New Zealand", Value: "nz"},
+				{Label: "Australia", Value: "au"},
+			}},
+			{Label: "Antarctica", Disabled: true, Options: []hmc.Option{
+				{Label: "Ross Dependency", Value: "aq-nz"},
+			}},
+		},
+	}
+	form := url.Values{
+		"region": {"nz"},
+	}
+	input.ExtractFormValue(form)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := tm.ExecuteTemplate(buf, "select", input)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, input)
+	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
+	assert.Eq(t, "value from group", "nz", input.Value())
+	assert.Eq(t, "all entries are extracted", 0, len(form))
+}
+
+func TestSnapshotOptionSource(t *testing.T) {
+	input := hmc.Select{
+		Label:      "Customer",
+		Name:       "customer",
+		Exhaustive: true,
+		Options: []hmc.Option{
+			{Label: "Acme Ltd", Value: "c-1", Selected: true},
+		},
+		Source: &hmc.OptionSource{Href: "/customers", SearchParam: "name"},
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	err := tm.ExecuteTemplate(buf, "select", input)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, input)
+	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
+}
+
+func TestSnapshotList(t *testing.T) {
+	input := lines()
+	input.ExtractFormValue(url.Values{
+		"lines[0][sku]": {"A1"},
+		"lines[0][qty]": {"0"},
+	})
+	input.Validate()
+
+	ltm := parseTemplates(t, `{{define "list_item"}}
+  {{template "input" .SKU}}
+  {{template "input" .Quantity}}
+  {{template "select" .Gift}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ltm.ExecuteTemplate(buf, "list", input)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, input)
+	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
+}
+
+func TestSnapshotFieldset(t *testing.T) {
+	f := newCheckout()
+	f.Elements.Billing.Elements.Street.Value = "1 Main St"
+	f.Validate()
+
+	ftm := parseTemplates(t, `{{define "fieldset_elements"}}
+  {{template "input" .Street}}
+  {{template "input" .City}}{{end}}`)
+
+	buf := bytes.NewBuffer([]byte{})
+	err := ftm.ExecuteTemplate(buf, "fieldset", f.Elements.Shipping)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, f)
+	assert.SnapshotJson(t, f)
+	roundTripJson(t, f)
+	roundTripXml(t, f)
 }
 
 func TestSnapshotMap(t *testing.T) {
@@ -209,9 +524,39 @@ func TestSnapshotMap(t *testing.T) {
 	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
 	assert.SnapshotXml(t, input)
 	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
 	assert.Eq(t, "only unmatched entries are still in form", 1, len(form))
 }
 
+func TestSnapshotMapConstraints(t *testing.T) {
+	input := hmc.Map{
+		Label:      "Labels",
+		Name:       "labels",
+		KeyPattern: `[a-z]+`,
+		Keys:       []string{"env", "team", "Owner"},
+		MaxEntries: 2,
+		MaxLength:  8,
+	}
+	form := url.Values{
+		"labels[env]":   {"production"},
+		"labels[team]":  {"web"},
+		"labels[Owner]": {"jo"},
+	}
+	input.ExtractFormValue(form)
+	input.Validate()
+
+	buf := bytes.NewBuffer([]byte{})
+	err := tm.ExecuteTemplate(buf, "map", input)
+	assert.FatalErr(t, "executing template", err)
+
+	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
+	assert.SnapshotXml(t, input)
+	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
+}
+
 func TestSnapshotBucket(t *testing.T) {
 	input := hmc.Map{Label: "Leftover data"}
 	form := url.Values{
@@ -229,5 +574,7 @@ func TestSnapshotBucket(t *testing.T) {
 	assert.Snapshot(t, fmt.Sprintf("%s.snap.html", t.Name()), buf.Bytes())
 	assert.SnapshotXml(t, input)
 	assert.SnapshotJson(t, input)
+	roundTripJson(t, input)
+	roundTripXml(t, input)
 	assert.Eq(t, "all entries are extracted by Map", 0, len(form))
 }
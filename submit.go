@@ -0,0 +1,129 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"reflect"
+	"slices"
+)
+
+// Submit is analogous to HTML's <button type="submit">. It is one of the
+// actions that a form can be submitted with, such as "Save" or "Save as
+// draft".
+//
+// As in HTML, the Name and Value of a Submit are only included in the
+// submission if it is the one used to submit the form, so that the handler
+// can tell the actions apart. See [Submit.ExtractFormValue].
+//
+// Method and Action override the [Form]'s own when the form is submitted
+// with this Submit, like HTML's formmethod and formaction. Action is
+// resolved as [Form.Action] is.
+type Submit struct {
+	Label  string `json:"label"`
+	Name   string `json:"name,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Method string `json:"method,omitempty"`
+	Action string `json:"action,omitempty"`
+	// Used is set by [Submit.ExtractFormValue] if the form was submitted
+	// with this Submit. It isn't marshalled.
+	Used bool `json:"-"`
+}
+
+func (Submit) controlName() string { return "Submit" }
+
+func (s *Submit) scope(from, to string) {
+	if s.Name != "" {
+		s.Name = rescopeName(s.Name, from, to)
+	}
+}
+
+// UnmarshalJSON replaces s with the Submit in data. Used isn't marshalled,
+// so it is always false afterwards.
+func (s *Submit) UnmarshalJSON(data []byte) error {
+	var j submitJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = Submit(j)
+	return nil
+}
+
+// ExtractFormValue sets [Submit.Used] if Value is among the values of
+// Name in form, and deletes that value from form.
+//
+// A Submit without a Name is never included in a submission, so Used is
+// never set for it.
+func (s *Submit) ExtractFormValue(form url.Values) {
+	if s.Name == "" {
+		return
+	}
+	values := form[s.Name]
+	i := slices.Index(values, s.Value)
+	if i < 0 {
+		return
+	}
+	s.Used = true
+	if len(values) > 1 {
+		form[s.Name] = slices.Delete(slices.Clone(values), i, i+1)
+	} else {
+		delete(form, s.Name)
+	}
+}
+
+// SubmittedBy returns the [Submit] in Elements that the form was
+// submitted with, after [Form.ExtractFormValue]. It reports false if the
+// submission didn't name one, such as when a browser submits a form by
+// pressing enter in a form without a named Submit.
+func (f *Form[T]) SubmittedBy() (*Submit, bool) {
+	var used *Submit
+	walk(reflect.ValueOf(&f.Elements).Elem(), func(v reflect.Value) bool {
+		s, ok := asAddressable(v).(*Submit)
+		if ok && s.Used && used == nil {
+			used = s
+		}
+		return ok
+	})
+	return used, used != nil
+}
+
+func (s Submit) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Submit"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: s.Label})
+	if s.Name != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: s.Name})
+	}
+	if s.Value != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "value"}, Value: s.Value})
+	}
+	if s.Method != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "method"}, Value: s.Method})
+	}
+	if s.Action != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "action"}, Value: s.Action})
+	}
+	return e.EncodeElement("", start)
+}
+
+// UnmarshalXML decodes a c:Submit element as marshalled by
+// [Submit.MarshalXML].
+func (s *Submit) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*s = Submit{}
+
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			s.Label = a.Value
+		case "name":
+			s.Name = a.Value
+		case "value":
+			s.Value = a.Value
+		case "method":
+			s.Method = a.Value
+		case "action":
+			s.Action = a.Value
+		}
+	}
+
+	return d.Skip()
+}
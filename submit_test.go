@@ -0,0 +1,71 @@
+package hmc_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+type article struct {
+	Title   hmc.Input
+	Publish hmc.Submit
+	Draft   hmc.Submit
+}
+
+func newArticle() hmc.Form[article] {
+	return hmc.Form[article]{
+		Method: "POST",
+		Elements: article{
+			Title:   hmc.Input{Label: "Title", Name: "title"},
+			Publish: hmc.Submit{Label: "Publish", Name: "action", Value: "publish"},
+			Draft:   hmc.Submit{Label: "Save as draft", Name: "action", Value: "draft", Action: "/drafts"},
+		},
+	}
+}
+
+func TestSubmittedBy(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		form     url.Values
+		expected string
+		leftover int
+	}{
+		{"draft", url.Values{"title": {"Hello"}, "action": {"draft"}}, "draft", 0},
+		{"publish", url.Values{"title": {"Hello"}, "action": {"publish"}}, "publish", 0},
+		{"unknown action", url.Values{"title": {"Hello"}, "action": {"unpublish"}}, "", 1},
+		{"no action", url.Values{"title": {"Hello"}}, "", 0},
+	} {
+		f := newArticle()
+		f.ExtractFormValue(c.form)
+
+		s, ok := f.SubmittedBy()
+		assert.Eq(t, c.name+": submitted by a submit", c.expected != "", ok)
+		if ok {
+			assert.Eq(t, c.name+": submitted by", c.expected, s.Value)
+		}
+		assert.Eq(t, c.name+": publish used", c.expected == "publish", f.Elements.Publish.Used)
+		assert.Eq(t, c.name+": draft used", c.expected == "draft", f.Elements.Draft.Used)
+		assert.Eq(t, c.name+": only unknown actions are left", c.leftover, len(c.form))
+	}
+}
+
+func TestSubmitInList(t *testing.T) {
+	l := hmc.List[struct {
+		Name   hmc.Input
+		Remove hmc.Submit
+	}]{Name: "tags"}
+	l.Prototype.Name = hmc.Input{Name: "name"}
+	l.Prototype.Remove = hmc.Submit{Label: "Remove", Name: "remove", Value: "1"}
+
+	l.ExtractFormValue(url.Values{
+		"tags[0][name]":   {"a"},
+		"tags[1][name]":   {"b"},
+		"tags[1][remove]": {"1"},
+	})
+
+	assert.Eq(t, "submit is scoped to its item", "tags[1][remove]", l.Items[1].Remove.Name)
+	assert.Eq(t, "first item's submit wasn't used", false, l.Items[0].Remove.Used)
+	assert.Eq(t, "second item's submit was used", true, l.Items[1].Remove.Used)
+}
@@ -0,0 +1,179 @@
+package hmc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Textarea is analogous to HTML's <textarea>. It represents a piece of
+// free text that may span multiple lines, such as a description.
+//
+// Rows and Cols are hints for the size of the control, in lines and
+// characters. Wrap is how long lines are wrapped, as with HTML's wrap
+// attribute: "soft" (the default) or "hard", which asks browsers to submit
+// the line breaks they display, in which case Cols should be set.
+//
+// Line breaks in Value are "\n", although browsers submit them as "\r\n".
+type Textarea struct {
+	Label     string   `json:"label"`
+	Name      string   `json:"name"`
+	Required  bool     `json:"required,omitempty"`
+	Value     string   `json:"value"`
+	Errors    []string `json:"errors,omitempty"`
+	MinLength uint     `json:"minlength,omitempty"`
+	MaxLength uint     `json:"maxlength,omitempty"`
+	Rows      uint     `json:"rows,omitempty"`
+	Cols      uint     `json:"cols,omitempty"`
+	Wrap      string   `json:"wrap,omitempty"`
+}
+
+func (Textarea) controlName() string { return "Textarea" }
+
+func (t *Textarea) scope(from, to string) { t.Name = rescopeName(t.Name, from, to) }
+
+func (t Textarea) addFormValues(values url.Values) { values.Add(t.Name, t.Value) }
+
+// UnmarshalJSON replaces t with the Textarea in data, so sizing and length
+// limits that data omits, such as Rows or MaxLength, are cleared.
+func (t *Textarea) UnmarshalJSON(data []byte) error {
+	var j textareaJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*t = Textarea(j)
+	return nil
+}
+
+// ExtractFormValue sets Value to the first value of Name in form, with its
+// line breaks normalised to "\n", and deletes that value from form.
+func (t *Textarea) ExtractFormValue(form url.Values) {
+	formValue, ok := form[t.Name]
+	if ok {
+		t.Value = strings.ReplaceAll(formValue[0], "\r\n", "\n")
+		if len(formValue[1:]) > 0 {
+			form[t.Name] = formValue[1:]
+		} else {
+			delete(form, t.Name)
+		}
+	}
+}
+
+// Validate checks Value against Required, MinLength and MaxLength, as
+// [Input.Validate] does for a text input, appending a message for each
+// problem to [Textarea.Errors]. The messages of an earlier call to Validate
+// are replaced, and other errors that are already present are kept.
+func (t *Textarea) Validate() {
+	i := Input{
+		Name:      t.Name,
+		Required:  t.Required,
+		Value:     t.Value,
+		MinLength: t.MinLength,
+		MaxLength: t.MaxLength,
+	}
+	t.Errors = append(inputMessages.clear(t.Errors), i.validationMessages()...)
+}
+
+// FirstError returns the first of [Textarea.Errors], or "" if there are
+// none.
+func (t Textarea) FirstError() string {
+	return firstError(t.Errors)
+}
+
+// FieldErrors reports each of [Textarea.Errors].
+func (t Textarea) FieldErrors() []FieldError {
+	return fieldErrors(t.Name, t.Errors)
+}
+
+// MarshalXML marshals the Textarea as a c:Textarea element. Unlike
+// [Input.MarshalXML], Value is the content of a c:Value child, so that long
+// text keeps its line breaks and stays readable. It is a child of its own,
+// apart from any c:Error, so that an indenting encoder can't add
+// whitespace to it.
+func (t Textarea) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "c:Textarea"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: t.Label})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "name"}, Value: t.Name})
+	if t.MinLength > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "minlength"}, Value: fmt.Sprintf("%d", t.MinLength)})
+	}
+	if t.MaxLength > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxlength"}, Value: fmt.Sprintf("%d", t.MaxLength)})
+	}
+	if t.Rows > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "rows"}, Value: fmt.Sprintf("%d", t.Rows)})
+	}
+	if t.Cols > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "cols"}, Value: fmt.Sprintf("%d", t.Cols)})
+	}
+	if t.Wrap != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "wrap"}, Value: t.Wrap})
+	}
+	if t.Required {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "required"}, Value: "true"})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	valueStart := xml.StartElement{Name: xml.Name{Local: "c:Value"}}
+	if err := e.EncodeToken(valueStart); err != nil {
+		return err
+	}
+	if err := e.EncodeToken(xml.CharData(t.Value)); err != nil {
+		return err
+	}
+	if err := e.EncodeToken(valueStart.End()); err != nil {
+		return err
+	}
+
+	if err := encodeErrors(e, t.Errors); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes a c:Textarea element as marshalled by
+// [Textarea.MarshalXML].
+func (t *Textarea) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*t = Textarea{}
+
+	for _, a := range start.Attr {
+		var err error
+		switch a.Name.Local {
+		case "label":
+			t.Label = a.Value
+		case "name":
+			t.Name = a.Value
+		case "minlength":
+			t.MinLength, err = parseUintAttr(a.Value)
+		case "maxlength":
+			t.MaxLength, err = parseUintAttr(a.Value)
+		case "rows":
+			t.Rows, err = parseUintAttr(a.Value)
+		case "cols":
+			t.Cols, err = parseUintAttr(a.Value)
+		case "wrap":
+			t.Wrap = a.Value
+		case "required":
+			t.Required = parseBoolAttr(a.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("c:Textarea %s attribute: %w", a.Name.Local, err)
+		}
+	}
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch {
+		case isControlElement(child.Name, "Value"):
+			return d.DecodeElement(&t.Value, &child)
+		case isControlElement(child.Name, "Error"):
+			return decodeError(d, child, &t.Errors)
+		}
+		return d.Skip()
+	})
+}
@@ -0,0 +1,50 @@
+package hmc_test
+
+import (
+	"encoding/xml"
+	"net/url"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func TestTextareaExtractFormValue(t *testing.T) {
+	ta := hmc.Textarea{Name: "bio"}
+	form := url.Values{"bio": {"line 1\r\nline 2"}}
+
+	ta.ExtractFormValue(form)
+
+	assert.Eq(t, "line breaks are normalised", "line 1\nline 2", ta.Value)
+	assert.Eq(t, "value is consumed", 0, len(form))
+}
+
+func TestTextareaValidate(t *testing.T) {
+	ta := hmc.Textarea{Name: "bio", Value: "ab\ncd", MaxLength: 4}
+	ta.Validate()
+	assert.SlicesEq(t, "errors", []string{`"bio" supports at most 4 characters (currently 5 characters)`}, ta.Errors)
+
+	ta = hmc.Textarea{Name: "bio", Required: true}
+	ta.Validate()
+	assert.SlicesEq(t, "required", []string{`"bio" is required`}, ta.Errors)
+
+	ta.Value = "abc"
+	ta.Validate()
+	assert.Eq(t, "errors are replaced when validated again", 0, len(ta.Errors))
+}
+
+func TestTextareaXmlValue(t *testing.T) {
+	for _, value := range []string{"", "one line", "two\nlines\n", "\n  indented", "line\n  "} {
+		for _, errors := range [][]string{nil, {"problem"}} {
+			ta := hmc.Textarea{Name: "bio", Value: value, Errors: errors}
+			data, err := xml.MarshalIndent(ta, "", "  ")
+			assert.FatalErr(t, "marshalling", err)
+
+			var decoded hmc.Textarea
+			err = xml.Unmarshal(data, &decoded)
+			assert.FatalErr(t, "unmarshalling", err)
+			assert.Eq(t, string(data), value, decoded.Value)
+			assert.SlicesEq(t, string(data), errors, decoded.Errors)
+		}
+	}
+}
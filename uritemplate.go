@@ -0,0 +1,300 @@
+package hmc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// uriOperator describes how an expression with a given operator is
+// expanded, following the table in appendix A of RFC 6570.
+type uriOperator struct {
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var uriOperators = map[byte]uriOperator{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+	'#': {first: "#", sep: ",", reserved: true},
+}
+
+// ExpandURITemplate expands the URI template tmpl, as defined by RFC 6570
+// up to and including level 4, with the given values.
+//
+// Each value may be a string, a []string for a list, or a
+// map[string]string for an associative array, whose entries are expanded
+// in order of their keys. Other values are formatted with [fmt.Sprint].
+// As in RFC 6570, a variable that is missing, nil, or an empty list or map
+// is undefined, and is left out of the expansion.
+//
+// An error is returned if tmpl isn't a valid URI template, or if a prefix
+// modifier is applied to a list or map.
+func ExpandURITemplate(tmpl string, values map[string]any) (string, error) {
+	var sb strings.Builder
+	rest := tmpl
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return "", fmt.Errorf("URI template %q: unexpected '}'", tmpl)
+			}
+			writeURIEncoded(&sb, rest, true)
+			break
+		}
+		if strings.IndexByte(rest[:open], '}') >= 0 {
+			return "", fmt.Errorf("URI template %q: unexpected '}'", tmpl)
+		}
+		writeURIEncoded(&sb, rest[:open], true)
+		rest = rest[open+1:]
+
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return "", fmt.Errorf("URI template %q: unclosed expression", tmpl)
+		}
+		if err := expandURIExpression(&sb, rest[:end], values); err != nil {
+			return "", fmt.Errorf("URI template %q: %w", tmpl, err)
+		}
+		rest = rest[end+1:]
+	}
+	return sb.String(), nil
+}
+
+// uriVarSpec is a variable of an expression, with its modifier.
+type uriVarSpec struct {
+	name    string
+	prefix  int
+	explode bool
+}
+
+// parseURIExpression parses the inside of an expression into its operator
+// and variables.
+func parseURIExpression(expr string) (byte, []uriVarSpec, error) {
+	var op byte
+	if expr != "" && strings.IndexByte("+./;?&#", expr[0]) >= 0 {
+		op = expr[0]
+		expr = expr[1:]
+	} else if expr != "" && strings.IndexByte("=,!@|", expr[0]) >= 0 {
+		return 0, nil, fmt.Errorf("reserved operator %q", expr[0])
+	}
+
+	var specs []uriVarSpec
+	for s := range strings.SplitSeq(expr, ",") {
+		spec := uriVarSpec{name: s}
+		if name, ok := strings.CutSuffix(s, "*"); ok {
+			spec = uriVarSpec{name: name, explode: true}
+		} else if name, length, ok := strings.Cut(s, ":"); ok {
+			n, err := strconv.Atoi(length)
+			if err != nil || n < 1 || n > 9999 || length[0] == '0' {
+				return 0, nil, fmt.Errorf("invalid prefix length %q", length)
+			}
+			spec = uriVarSpec{name: name, prefix: n}
+		}
+		if !validURIVarName(spec.name) {
+			return 0, nil, fmt.Errorf("invalid variable name %q", spec.name)
+		}
+		specs = append(specs, spec)
+	}
+	return op, specs, nil
+}
+
+// validURIVarName reports whether name is a varname of RFC 6570: made of
+// letters, digits, underscores and percent-encoded triplets, with dots
+// between them.
+func validURIVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' || strings.Contains(name, "..") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(name) || !isHex(name[i+1]) || !isHex(name[i+2]) {
+				return false
+			}
+			i += 2
+		case c == '_' || c == '.' || isAlphaNum(c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func expandURIExpression(sb *strings.Builder, expr string, values map[string]any) error {
+	opChar, specs, err := parseURIExpression(expr)
+	if err != nil {
+		return err
+	}
+	op := uriOperators[opChar]
+
+	defined := false
+	for _, spec := range specs {
+		value, ok := uriValue(values[spec.name])
+		if !ok {
+			continue
+		}
+		if defined {
+			sb.WriteString(op.sep)
+		} else {
+			sb.WriteString(op.first)
+			defined = true
+		}
+
+		switch v := value.(type) {
+		case string:
+			if spec.prefix > 0 {
+				v = uriPrefix(v, spec.prefix)
+			}
+			op.writeNamed(sb, spec.name, v)
+		case []string:
+			if spec.prefix > 0 {
+				return fmt.Errorf("prefix modifier on list %q", spec.name)
+			}
+			if !spec.explode {
+				if op.named {
+					sb.WriteString(spec.name + "=")
+				}
+				for i, item := range v {
+					if i > 0 {
+						sb.WriteString(",")
+					}
+					writeURIEncoded(sb, item, op.reserved)
+				}
+				continue
+			}
+			for i, item := range v {
+				if i > 0 {
+					sb.WriteString(op.sep)
+				}
+				if op.named {
+					op.writeNamed(sb, spec.name, item)
+				} else {
+					writeURIEncoded(sb, item, op.reserved)
+				}
+			}
+		case map[string]string:
+			if spec.prefix > 0 {
+				return fmt.Errorf("prefix modifier on map %q", spec.name)
+			}
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if !spec.explode {
+				if op.named {
+					sb.WriteString(spec.name + "=")
+				}
+				for i, k := range keys {
+					if i > 0 {
+						sb.WriteString(",")
+					}
+					writeURIEncoded(sb, k, op.reserved)
+					sb.WriteString(",")
+					writeURIEncoded(sb, v[k], op.reserved)
+				}
+				continue
+			}
+			for i, k := range keys {
+				if i > 0 {
+					sb.WriteString(op.sep)
+				}
+				if op.named {
+					var name strings.Builder
+					writeURIEncoded(&name, k, op.reserved)
+					op.writeNamed(sb, name.String(), v[k])
+				} else {
+					writeURIEncoded(sb, k, op.reserved)
+					sb.WriteString("=")
+					writeURIEncoded(sb, v[k], op.reserved)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeNamed writes value, preceded by name if the operator is named.
+func (op uriOperator) writeNamed(sb *strings.Builder, name, value string) {
+	if op.named {
+		sb.WriteString(name)
+		if value == "" {
+			sb.WriteString(op.ifEmpty)
+			return
+		}
+		sb.WriteString("=")
+	}
+	writeURIEncoded(sb, value, op.reserved)
+}
+
+// uriValue normalises v to a string, []string or map[string]string, and
+// reports whether it is defined.
+func uriValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return v, true
+	case []string:
+		return v, len(v) > 0
+	case map[string]string:
+		return v, len(v) > 0
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// uriPrefix returns the first n characters of s.
+func uriPrefix(s string, n int) string {
+	i := 0
+	for range n {
+		if i >= len(s) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i]
+}
+
+// writeURIEncoded writes s, percent-encoding every byte that isn't
+// unreserved, or, if reserved is set, that isn't reserved either or part
+// of a percent-encoded triplet.
+func writeURIEncoded(sb *strings.Builder, s string, reserved bool) {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlphaNum(c) || strings.IndexByte("-._~", c) >= 0:
+			sb.WriteByte(c)
+		case reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			sb.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			sb.WriteString(s[i : i+3])
+			i += 2
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&15])
+		}
+	}
+}
+
+func isAlphaNum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
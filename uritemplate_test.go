@@ -0,0 +1,204 @@
+package hmc_test
+
+import (
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+// uriTemplateValues are the variables used by the examples in section 3.2
+// of RFC 6570.
+var uriTemplateValues = map[string]any{
+	"count":      []string{"one", "two", "three"},
+	"dom":        []string{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []string{"red", "green", "blue"},
+	"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+	"v":          "6",
+	"x":          "1024",
+	"y":          "768",
+	"empty":      "",
+	"empty_keys": map[string]string{},
+	"undef":      nil,
+}
+
+func TestExpandURITemplate(t *testing.T) {
+	// The examples of RFC 6570, section 3.2, except that the entries of
+	// "keys" are expanded in order of their keys.
+	examples := []struct{ template, expected string }{
+		{"{count}", "one,two,three"},
+		{"{count*}", "one,two,three"},
+		{"{/count}", "/one,two,three"},
+		{"{/count*}", "/one/two/three"},
+		{"{;count}", ";count=one,two,three"},
+		{"{;count*}", ";count=one;count=two;count=three"},
+		{"{?count}", "?count=one,two,three"},
+		{"{?count*}", "?count=one&count=two&count=three"},
+		{"{&count*}", "&count=one&count=two&count=three"},
+
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{half}", "50%25"},
+		{"O{empty}X", "OX"},
+		{"O{undef}X", "OX"},
+		{"{x,y}", "1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"?{x,empty}", "?1024,"},
+		{"?{x,undef}", "?1024"},
+		{"?{undef,y}", "?768"},
+		{"{var:3}", "val"},
+		{"{var:30}", "value"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+
+		{"{+var}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+half}", "50%25"},
+		{"{base}index", "http%3A%2F%2Fexample.com%2Fhome%2Findex"},
+		{"{+base}index", "http://example.com/home/index"},
+		{"O{+empty}X", "OX"},
+		{"O{+undef}X", "OX"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"up{+path}{var}/here", "up/foo/barvalue/here"},
+		{"{+x,hello,y}", "1024,Hello%20World!,768"},
+		{"{+path,x}/here", "/foo/bar,1024/here"},
+		{"{+path:6}/here", "/foo/b/here"},
+		{"{+list}", "red,green,blue"},
+		{"{+list*}", "red,green,blue"},
+		{"{+keys}", "comma,,,dot,.,semi,;"},
+		{"{+keys*}", "comma=,,dot=.,semi=;"},
+
+		{"{#var}", "#value"},
+		{"{#hello}", "#Hello%20World!"},
+		{"{#half}", "#50%25"},
+		{"foo{#empty}", "foo#"},
+		{"foo{#undef}", "foo"},
+		{"{#x,hello,y}", "#1024,Hello%20World!,768"},
+		{"{#path,x}/here", "#/foo/bar,1024/here"},
+		{"{#path:6}/here", "#/foo/b/here"},
+		{"{#list}", "#red,green,blue"},
+		{"{#list*}", "#red,green,blue"},
+		{"{#keys}", "#comma,,,dot,.,semi,;"},
+		{"{#keys*}", "#comma=,,dot=.,semi=;"},
+
+		{"{.who}", ".fred"},
+		{"{.who,who}", ".fred.fred"},
+		{"{.half,who}", ".50%25.fred"},
+		{"www{.dom*}", "www.example.com"},
+		{"X{.var}", "X.value"},
+		{"X{.empty}", "X."},
+		{"X{.undef}", "X"},
+		{"X{.var:3}", "X.val"},
+		{"X{.list}", "X.red,green,blue"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"X{.keys}", "X.comma,%2C,dot,.,semi,%3B"},
+		{"X{.keys*}", "X.comma=%2C.dot=..semi=%3B"},
+		{"X{.empty_keys}", "X"},
+		{"X{.empty_keys*}", "X"},
+
+		{"{/who}", "/fred"},
+		{"{/who,who}", "/fred/fred"},
+		{"{/half,who}", "/50%25/fred"},
+		{"{/who,dub}", "/fred/me%2Ftoo"},
+		{"{/var}", "/value"},
+		{"{/var,empty}", "/value/"},
+		{"{/var,undef}", "/value"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/var:1,var}", "/v/value"},
+		{"{/list}", "/red,green,blue"},
+		{"{/list*}", "/red/green/blue"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{/keys}", "/comma,%2C,dot,.,semi,%3B"},
+		{"{/keys*}", "/comma=%2C/dot=./semi=%3B"},
+
+		{"{;who}", ";who=fred"},
+		{"{;half}", ";half=50%25"},
+		{"{;empty}", ";empty"},
+		{"{;v,empty,who}", ";v=6;empty;who=fred"},
+		{"{;v,bar,who}", ";v=6;who=fred"},
+		{"{;x,y}", ";x=1024;y=768"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{;x,y,undef}", ";x=1024;y=768"},
+		{"{;hello:5}", ";hello=Hello"},
+		{"{;list}", ";list=red,green,blue"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{;keys}", ";keys=comma,%2C,dot,.,semi,%3B"},
+		{"{;keys*}", ";comma=%2C;dot=.;semi=%3B"},
+
+		{"{?who}", "?who=fred"},
+		{"{?half}", "?half=50%25"},
+		{"{?x,y}", "?x=1024&y=768"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?x,y,undef}", "?x=1024&y=768"},
+		{"{?var:3}", "?var=val"},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?list*}", "?list=red&list=green&list=blue"},
+		{"{?keys}", "?keys=comma,%2C,dot,.,semi,%3B"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+
+		{"{&who}", "&who=fred"},
+		{"{&half}", "&half=50%25"},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{&x,y,empty}", "&x=1024&y=768&empty="},
+		{"{&var:3}", "&var=val"},
+		{"{&list}", "&list=red,green,blue"},
+		{"{&list*}", "&list=red&list=green&list=blue"},
+		{"{&keys}", "&keys=comma,%2C,dot,.,semi,%3B"},
+		{"{&keys*}", "&comma=%2C&dot=.&semi=%3B"},
+	}
+
+	for _, e := range examples {
+		actual, err := hmc.ExpandURITemplate(e.template, uriTemplateValues)
+		assert.FatalErr(t, e.template, err)
+		assert.Eq(t, e.template, e.expected, actual)
+	}
+}
+
+func TestExpandURITemplateValues(t *testing.T) {
+	actual, err := hmc.ExpandURITemplate("/orders/{id}{?page}", map[string]any{"id": 42, "page": 3})
+	assert.FatalErr(t, "numbers", err)
+	assert.Eq(t, "numbers", "/orders/42?page=3", actual)
+
+	actual, err = hmc.ExpandURITemplate("{name:2}", map[string]any{"name": "Ünïcode"})
+	assert.FatalErr(t, "prefix of unicode", err)
+	assert.Eq(t, "prefix of unicode", "%C3%9Cn", actual)
+
+	actual, err = hmc.ExpandURITemplate("/wiki/{+page}", map[string]any{"page": "caf%C3%A9 au lait"})
+	assert.FatalErr(t, "pct-encoded in reserved", err)
+	assert.Eq(t, "pct-encoded in reserved", "/wiki/caf%C3%A9%20au%20lait", actual)
+
+	actual, err = hmc.ExpandURITemplate("/a b/{x}", map[string]any{"x": "y"})
+	assert.FatalErr(t, "literal", err)
+	assert.Eq(t, "literal", "/a%20b/y", actual)
+}
+
+func TestExpandURITemplateInvalid(t *testing.T) {
+	for _, template := range []string{
+		"{var",
+		"var}",
+		"{var}}",
+		"{}",
+		"{=var}",
+		"{var:0}",
+		"{var:10000}",
+		"{var:x}",
+		"{va r}",
+		"{.var.}",
+		"{var,}",
+		"{list:3}",
+		"{keys:3}",
+	} {
+		_, err := hmc.ExpandURITemplate(template, uriTemplateValues)
+		assert.True(t, template, err != nil)
+	}
+}
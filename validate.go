@@ -0,0 +1,137 @@
+package hmc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+)
+
+// FieldError describes why the value submitted for the control named Name
+// is invalid.
+type FieldError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Message)
+}
+
+// Validator is implemented by controls that can check their own value,
+// recording any problems on the control itself.
+type Validator interface {
+	Validate()
+}
+
+// ErrorReporter is implemented by controls that hold validation errors.
+type ErrorReporter interface {
+	FieldErrors() []FieldError
+}
+
+// isControl reports whether c is a control that validateControls and
+// controlErrors should stop at, rather than looking inside it.
+func isControl(c any) bool {
+	_, isValidator := c.(Validator)
+	_, isReporter := c.(ErrorReporter)
+	return isValidator || isReporter
+}
+
+// validateControls calls Validate on every [Validator] in v.
+func validateControls(v reflect.Value) {
+	walk(v, func(v reflect.Value) bool {
+		c := asAddressable(v)
+		if validator, ok := c.(Validator); ok {
+			validator.Validate()
+		}
+		return isControl(c)
+	})
+}
+
+// controlErrors collects the errors of every [ErrorReporter] in v, in
+// declaration order.
+func controlErrors(v reflect.Value) []FieldError {
+	var errs []FieldError
+	walk(v, func(v reflect.Value) bool {
+		c := asAddressable(v)
+		if reporter, ok := c.(ErrorReporter); ok {
+			errs = append(errs, reporter.FieldErrors()...)
+		}
+		return isControl(c)
+	})
+	return errs
+}
+
+func fieldErrors(name string, messages []string) []FieldError {
+	if len(messages) == 0 {
+		return nil
+	}
+	errs := make([]FieldError, len(messages))
+	for i, msg := range messages {
+		errs[i] = FieldError{Name: name, Message: msg}
+	}
+	return errs
+}
+
+// messageFormats matches the messages a control's Validate makes, so that
+// they can be cleared before validating again without clearing the errors
+// added by other validation.
+type messageFormats []*regexp.Regexp
+
+var formatVerb = regexp.MustCompile(`%#?[dsv]`)
+
+// newMessageFormats matches messages made by fmt.Sprintf with any of
+// formats, whatever the arguments were.
+func newMessageFormats(formats ...string) messageFormats {
+	m := make(messageFormats, len(formats))
+	for i, f := range formats {
+		pattern := formatVerb.ReplaceAllStringFunc(regexp.QuoteMeta(f), func(verb string) string {
+			if verb == "%d" {
+				return "-?[0-9]+"
+			}
+			return "(?s:.*)"
+		})
+		m[i] = regexp.MustCompile("^" + pattern + "$")
+	}
+	return m
+}
+
+// clear returns messages without those matched by m.
+func (m messageFormats) clear(messages []string) []string {
+	var kept []string
+	for _, msg := range messages {
+		if !slices.ContainsFunc(m, func(re *regexp.Regexp) bool { return re.MatchString(msg) }) {
+			kept = append(kept, msg)
+		}
+	}
+	return kept
+}
+
+func firstError(messages []string) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[0]
+}
+
+// encodeErrors encodes each message as a c:Error element.
+func encodeErrors(e *xml.Encoder, messages []string) error {
+	errorStart := xml.StartElement{Name: xml.Name{Local: "c:Error"}}
+	for _, msg := range messages {
+		if err := e.EncodeElement(msg, errorStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeError decodes the c:Error element start, appending it to messages.
+func decodeError(d *xml.Decoder, start xml.StartElement, messages *[]string) error {
+	var msg string
+	if err := d.DecodeElement(&msg, &start); err != nil {
+		return err
+	}
+	*messages = append(*messages, msg)
+	return nil
+}
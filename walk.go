@@ -0,0 +1,115 @@
+package hmc
+
+import "reflect"
+
+// walk visits v and then, depth-first and in declaration order, every value
+// reachable from it through exported struct fields, pointers and
+// interfaces, slices and arrays. Nil pointers and interfaces are skipped.
+//
+// visit is called before a value's children are visited. If it returns true
+// the value is considered handled and its children are skipped.
+func walk(v reflect.Value, visit func(reflect.Value) bool) {
+	if !v.IsValid() {
+		return
+	}
+
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return
+	}
+
+	if visit(v) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		walk(v.Elem(), visit)
+	case reflect.Struct:
+		t := v.Type()
+		for i := range v.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			walk(v.Field(i), visit)
+		}
+	case reflect.Slice, reflect.Array:
+		if !mayHoldControls(v.Type().Elem()) {
+			return
+		}
+		for i := range v.Len() {
+			walk(v.Index(i), visit)
+		}
+	}
+}
+
+// mayHoldControls reports whether values of type t could contain a control,
+// so that walk doesn't needlessly step through things like []byte.
+func mayHoldControls(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// asAddressable returns the interface of a pointer to v if v is
+// addressable, so that pointer-receiver methods are found; otherwise it
+// returns v's own interface.
+func asAddressable(v reflect.Value) any {
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// deepCopy returns a copy of v that shares no pointers, slices or maps
+// with it, so that a prototype can be copied and the copy's controls
+// changed without changing the prototype. Unexported fields are copied
+// shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			c.Set(reflect.New(v.Type().Elem()))
+			c.Elem().Set(deepCopy(v.Elem()))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			c.Set(deepCopy(v.Elem()))
+		}
+	case reflect.Struct:
+		c.Set(v)
+		t := v.Type()
+		for i := range v.NumField() {
+			if t.Field(i).IsExported() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := range v.Len() {
+				c.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			for iter := v.MapRange(); iter.Next(); {
+				c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+			}
+		}
+	default:
+		c.Set(v)
+	}
+	return c
+}
@@ -0,0 +1,258 @@
+package hmc
+
+import (
+	"cmp"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// control is implemented by each hypermedia control, giving the local name
+// of the element it is marshalled as under the c: namespace.
+type control interface {
+	controlName() string
+}
+
+// isControlElement reports whether name is the element of a hypermedia
+// control called local.
+func isControlElement(name xml.Name, local string) bool {
+	l, ok := ControlElementName(name)
+	return ok && l == local
+}
+
+// decodeChildren reads tokens up to the end of the current element, calling
+// child for each child element. child must consume the whole element, e.g.
+// with [xml.Decoder.DecodeElement] or [xml.Decoder.Skip].
+func decodeChildren(d *xml.Decoder, child func(start xml.StartElement) error) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if err := child(tok); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// parseBoolAttr interprets a boolean attribute. Like HTML, the presence
+// of the attribute means true, but an explicit "false" is also respected.
+func parseBoolAttr(value string) bool {
+	return value != "false"
+}
+
+func parseUintAttr(value string) (uint, error) {
+	n, err := strconv.ParseUint(value, 10, 0)
+	return uint(n), err
+}
+
+// xmlField is a struct field that is marshalled as a child element.
+type xmlField struct {
+	name  string
+	value reflect.Value
+}
+
+// xmlFields lists the fields of the struct v that encoding/xml would
+// marshal as child elements, in declaration order, flattening embedded
+// structs as encoding/xml does.
+func xmlFields(v reflect.Value) []xmlField {
+	var fields []xmlField
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, opts, _ := strings.Cut(sf.Tag.Get("xml"), ",")
+		if tag == "-" || opts != "" && opts != "omitempty" {
+			continue
+		}
+		if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct && !isUnmarshaler(sf.Type) {
+			fields = append(fields, xmlFields(v.Field(i))...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if _, local, ok := strings.Cut(tag, " "); ok {
+			tag = local
+		}
+		fields = append(fields, xmlField{name: cmp.Or(tag, sf.Name), value: v.Field(i)})
+	}
+	return fields
+}
+
+func isUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(reflect.TypeFor[xml.Unmarshaler]())
+}
+
+// controlTypeName returns the element name of the control type t, looking
+// through pointers.
+func controlTypeName(t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	c, ok := reflect.Zero(t).Interface().(control)
+	if !ok {
+		return "", false
+	}
+	return c.controlName(), true
+}
+
+// decodeValue decodes the element start into v, which must be settable.
+//
+// Structs that don't implement [xml.Unmarshaler] themselves are decoded
+// with decodeControls so that any controls inside them are found.
+func decodeValue(d *xml.Decoder, v reflect.Value, start xml.StartElement) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(d, v.Elem(), start)
+	case isUnmarshaler(v.Type()):
+		return d.DecodeElement(v.Addr().Interface(), &start)
+	case v.Kind() == reflect.Struct:
+		return decodeControls(d, v, start)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		return decodeValue(d, v.Index(v.Len()-1), start)
+	default:
+		return d.DecodeElement(v.Addr().Interface(), &start)
+	}
+}
+
+// decodeControls decodes the children of start into the struct v.
+//
+// Every control is marshalled under the same element name regardless of
+// the field it came from, so control elements are matched to control
+// fields positionally, in declaration order. A field holding a slice of
+// controls takes every consecutive element of its type. Other elements are
+// matched to fields by name, as encoding/xml would.
+//
+// A nil pointer to a control is optional, since encoding/xml omits it when
+// marshalling, so it only takes an element if there are more elements of
+// its type left than later fields of that type that aren't optional.
+// Otherwise the element is left for those fields. If the number of
+// elements doesn't say which optional fields were marshalled, such as when
+// optional fields come both before and after a field of the same type, or
+// before a slice of it, the earlier ones are filled first.
+func decodeControls(d *xml.Decoder, v reflect.Value, start xml.StartElement) error {
+	children, err := bufferChildren(d, start)
+	if err != nil {
+		return err
+	}
+
+	fields := xmlFields(v)
+	next := 0
+	child := 0
+	d = xml.NewTokenDecoder(&children)
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	return decodeChildren(d, func(start xml.StartElement) error {
+		remaining := children.starts[child:]
+		child++
+		if local, ok := ControlElementName(start.Name); ok {
+			for i := next; i < len(fields); i++ {
+				f := fields[i].value
+				t := f.Type()
+				if t.Kind() == reflect.Slice {
+					t = t.Elem()
+				}
+				if name, ok := controlTypeName(t); !ok || name != local {
+					continue
+				}
+				if f.Kind() == reflect.Pointer && f.IsNil() && !bindsOptional(fields[i+1:], local, remaining) {
+					continue
+				}
+				next = i
+				if f.Kind() != reflect.Slice {
+					next++
+				}
+				return decodeValue(d, f, start)
+			}
+			return d.Skip()
+		}
+
+		for _, f := range fields {
+			if f.name == start.Name.Local {
+				return decodeValue(d, f.value, start)
+			}
+		}
+		return d.Skip()
+	})
+}
+
+// bindsOptional reports whether an optional control field of the element
+// local, followed by the fields later, should take the first of the child
+// elements remaining.
+func bindsOptional(later []xmlField, local string, remaining []xml.StartElement) bool {
+	required := 0
+	for _, f := range later {
+		v := f.value
+		if name, ok := controlTypeName(v.Type()); !ok || name != local || v.Kind() == reflect.Pointer && v.IsNil() {
+			continue
+		}
+		if v.Kind() != reflect.Slice {
+			required++
+		}
+	}
+
+	count := 0
+	for _, start := range remaining {
+		if l, ok := ControlElementName(start.Name); ok && l == local {
+			count++
+		}
+	}
+	return count > required
+}
+
+// tokenBuffer holds the tokens of an element's children, so that they can
+// be looked ahead at before they are decoded.
+type tokenBuffer struct {
+	tokens []xml.Token
+	// starts are the start elements of the children, in order.
+	starts []xml.StartElement
+}
+
+// Token implements [xml.TokenReader].
+func (b *tokenBuffer) Token() (xml.Token, error) {
+	if len(b.tokens) == 0 {
+		return nil, io.EOF
+	}
+	tok := b.tokens[0]
+	b.tokens = b.tokens[1:]
+	return tok, nil
+}
+
+// bufferChildren reads the tokens up to and including the end of the
+// element start, which has just been read, and buffers them after start.
+func bufferChildren(d *xml.Decoder, start xml.StartElement) (tokenBuffer, error) {
+	b := tokenBuffer{tokens: []xml.Token{start.Copy()}}
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return b, err
+		}
+		tok = xml.CopyToken(tok)
+		b.tokens = append(b.tokens, tok)
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				b.starts = append(b.starts, tok)
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return b, nil
+			}
+			depth--
+		}
+	}
+}
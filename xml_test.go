@@ -0,0 +1,173 @@
+package hmc_test
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/Teajey/hmc"
+	"github.com/Teajey/hmc/internal/assert"
+)
+
+func roundTripXml[T any](t *testing.T, expected T) {
+	t.Helper()
+
+	data, err := xml.Marshal(expected)
+	assert.FatalErr(t, "marshalling", err)
+
+	var actual T
+	err = xml.Unmarshal(data, &actual)
+	assert.FatalErr(t, "unmarshalling", err)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("round trip through %s:\n%#v\n!=\n%#v", data, expected, actual)
+	}
+}
+
+func TestXmlRoundTripInput(t *testing.T) {
+	roundTripXml(t, hmc.Input{
+		Label:     "Age",
+		Type:      "number",
+		Name:      "age",
+		Required:  true,
+		Value:     "30",
+		Errors:    []string{"too old", "too young"},
+		MinLength: 1,
+		MaxLength: 3,
+		Step:      0.5,
+		Min:       "0",
+		Max:       "29",
+	})
+}
+
+func TestXmlRoundTripSelect(t *testing.T) {
+	roundTripXml(t, hmc.Select{
+		Multiple:   true,
+		Exhaustive: true,
+		Label:      "Favourite animals",
+		Name:       "fav_anim",
+		Required:   true,
+		Options: []hmc.Option{
+			{Value: "mouse", Selected: true},
+			{Label: "Dog", Value: "dog", Selected: true},
+			{Label: "Cat", Value: "cat", Disabled: true},
+			{},
+		},
+		Errors: []string{"no mice"},
+	})
+}
+
+func TestXmlRoundTripMap(t *testing.T) {
+	roundTripXml(t, hmc.Map{
+		Label: "Random data",
+		Name:  "data",
+		Entries: map[string][]string{
+			"drinks": {"water", "tea"},
+			"food":   {"icecream"},
+		},
+		Errors: []string{"too random"},
+	})
+	roundTripXml(t, hmc.Map{
+		Label: "Leftovers",
+		Entries: map[string][]string{
+			"tree": {"oak"},
+		},
+		Nested: map[string]*hmc.Map{
+			"data": {Name: "data", Entries: map[string][]string{"food": {"icecream"}}},
+		},
+	})
+}
+
+func TestXmlRoundTripLink(t *testing.T) {
+	roundTripXml(t, hmc.Link{Label: "Register", Href: "/register?a=1&b=2"})
+}
+
+type contact struct {
+	Phone *hmc.Input
+	Email hmc.Input
+	Fax   *hmc.Input
+	Notes []hmc.Input
+}
+
+func TestXmlRoundTripOptionalControls(t *testing.T) {
+	phone := &hmc.Input{Label: "Phone", Name: "phone", Value: "555"}
+	email := hmc.Input{Label: "Email", Name: "email", Value: "jo@example.com"}
+	fax := &hmc.Input{Label: "Fax", Name: "fax"}
+	notes := []hmc.Input{{Name: "notes[0]"}, {Name: "notes[1]"}}
+
+	for _, c := range []contact{
+		{Email: email},
+		{Phone: phone, Email: email},
+		{Phone: phone, Email: email, Fax: fax},
+		{Phone: phone, Email: email, Fax: fax, Notes: notes},
+	} {
+		roundTripXml(t, hmc.Form[contact]{Elements: c})
+	}
+}
+
+type decodedPage struct {
+	XMLName xml.Name `xml:"myPage"`
+	Title   string
+	Form    hmc.Form[login] `xml:"Form"`
+}
+
+func TestXmlRoundTripForm(t *testing.T) {
+	roundTripXml(t, hmc.Form[order]{
+		Method:  "POST",
+		Action:  "/orders",
+		Enctype: hmc.EnctypeMultipart,
+		Elements: order{
+			Name: hmc.Input{Label: "Name", Name: "name", Value: "Jo"},
+			Address: address{
+				Street: hmc.Input{Name: "street", Value: "1 Main St"},
+				City:   &hmc.Input{Name: "city", Errors: []string{"unknown city"}},
+			},
+			Extras: []hmc.Select{
+				{Name: "sauce", Options: []hmc.Option{{Value: "bbq", Selected: true}}},
+				{Name: "side", Options: []hmc.Option{{Value: "chips"}}},
+			},
+			Leftovers: hmc.Map{Label: "Notes"},
+		},
+	})
+}
+
+func TestXmlUnmarshalSnapshotForm(t *testing.T) {
+	data := []byte(`<myPage xmlns:c="https://github.com/Teajey/hmc">
+  <Title>Login</Title>
+  <c:Form method="POST" action="/login">
+    <login>
+      <c:Input label="Username" name="username" value="john" required="true"></c:Input>
+      <c:Input label="Password" name="password" type="password" value="********" required="true"></c:Input>
+      <c:Input label="Confirm password" name="confirmPassword" type="password" value="" required="true">
+        <c:Error>&#34;confirmPassword&#34; is required</c:Error>
+      </c:Input>
+      <c:Select label="Favourite food" name="favFood" required="true">
+        <c:Option>fruit</c:Option>
+        <c:Option selected="" value="bugs">Bugs</c:Option>
+      </c:Select>
+      <c:Map label="Misc" name="misc">
+        <c:Input name="misc[iq]" value="80"></c:Input>
+      </c:Map>
+      <c:Link href="/register">Register</c:Link>
+    </login>
+  </c:Form>
+</myPage>`)
+
+	var page decodedPage
+	err := xml.Unmarshal(data, &page)
+	assert.FatalErr(t, "unmarshalling", err)
+
+	e := page.Form.Elements
+	assert.Eq(t, "title", "Login", page.Title)
+	assert.Eq(t, "method", "POST", page.Form.Method)
+	assert.Eq(t, "action", "/login", page.Form.Action)
+	assert.Eq(t, "username", "john", e.Username.Value)
+	assert.Eq(t, "username required", true, e.Username.Required)
+	assert.Eq(t, "masked password is withheld", "", e.Password.Value)
+	assert.Eq(t, "password type", "password", e.Password.Type)
+	assert.Eq(t, "error", `"confirmPassword" is required`, e.ConfirmPassword.FirstError())
+	assert.Eq(t, "selected option", "bugs", e.FavouriteFood.Value())
+	assert.Eq(t, "option label", "Bugs", e.FavouriteFood.Options[1].Label)
+	assert.SlicesEq(t, "map entry", []string{"80"}, e.Misc.Entries["iq"])
+	assert.Eq(t, "link", hmc.Link{Label: "Register", Href: "/register"}, e.Login)
+}